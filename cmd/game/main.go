@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 
-	"github.com/Zarux/ticntacntoen/pkg/mcts"
 	"github.com/Zarux/ticntacntoen/services/game"
 )
 
@@ -15,8 +14,6 @@ var (
 func main() {
 	flag.Parse()
 
-	bot := mcts.New(*concFlag, *iterFlag)
-
-	gameService := game.New(bot)
+	gameService := game.New(*concFlag, *iterFlag)
 	gameService.Play()
 }
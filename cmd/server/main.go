@@ -2,19 +2,30 @@ package main
 
 import (
 	"context"
+	"flag"
 	"net/http"
 	"slices"
 
 	"github.com/Zarux/ticntacntoen/internal/logger"
-	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/bot"
 	"github.com/Zarux/ticntacntoen/services/ticntacntoen"
 )
 
+var botFlag = flag.String("bot", bot.MCTS.String(), "bot to play against: mcts or solver")
+
 func main() {
+	flag.Parse()
 	log := logger.New()
 
-	bot := mcts.New(4, 100_000)
-	svc := ticntacntoen.New(bot)
+	kind, err := bot.ParseKind(*botFlag)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+
+	svc := ticntacntoen.New(func() bot.Bot {
+		return bot.New(kind, 4, 100_000)
+	})
 
 	h := ticntacntoen.HTTPHandler(svc)
 	handler := rootHandler("/game/v1", h)
@@ -38,7 +49,7 @@ func main() {
 
 	addr := "127.0.0.1:3000"
 	log.Info("listening on", "addr", addr)
-	err := http.ListenAndServe(addr, handler)
+	err = http.ListenAndServe(addr, handler)
 	if err != nil {
 		log.Error(err.Error())
 	}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/solver"
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+// Bot is what the services need from a bot to play a game against it.
+type Bot interface {
+	GetNextMove(context.Context, *tictactoe.Board, tictactoe.Player) (int, error)
+	ChooseOpening(context.Context, *tictactoe.Game) (tictactoe.Choice, error)
+	Stats() *mcts.LastMoveStats
+	UpdateThinkTime(t time.Duration)
+}
+
+type Kind int8
+
+const (
+	MCTS Kind = iota
+	// Solver plays perfectly on boards small enough to solve in the think
+	// time and searches like MCTS on the rest.
+	Solver
+)
+
+var kindNames = []string{"mcts", "solver"}
+
+// Kinds lists every kind of bot.
+var Kinds = []Kind{MCTS, Solver}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", k)
+	}
+
+	return kindNames[k]
+}
+
+func ParseKind(s string) (Kind, error) {
+	for i, name := range kindNames {
+		if name == s {
+			return Kind(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown bot %q", s)
+}
+
+// New returns a bot of the given kind, searching with workers each running up
+// to iterations iterations.
+func New(kind Kind, workers, iterations int) Bot {
+	search := mcts.New(workers, iterations)
+	if kind == Solver {
+		return solver.New(search)
+	}
+
+	return search
+}
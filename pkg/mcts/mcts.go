@@ -65,7 +65,9 @@ func (c *Client) getNewRoot(b *tictactoe.Board) (newRoot *node) {
 	defer func() {
 		newRoot.Parent = nil
 		var untriedMoves []int
-		legalMoves := b.LegalMoves()
+		// Moves that only differ by a symmetry of the position lead to the
+		// same subtree, so only one of them is searched.
+		legalMoves := b.UniqueMoves(b.LegalMoves())
 	legalMoveLoop:
 		for _, move := range legalMoves {
 			for _, child := range newRoot.Children {
@@ -80,20 +82,19 @@ func (c *Client) getNewRoot(b *tictactoe.Board) (newRoot *node) {
 		newRoot.UntriedMoves = untriedMoves
 	}()
 
+	newRoot.Hash = b.Hash
+
 	if c.lastNode == nil {
 		return newRoot
 	}
 
-	if c.lastNode.Move == b.LastMove {
-		c.lastNode.Parent = nil
-		return c.lastNode
-	}
-
-	for _, childNode := range c.lastNode.Children {
-		if childNode.Move == b.LastMove {
-			childNode.Parent = nil
-			return childNode
-		}
+	// Every other player has had a turn since the bot last moved, and the
+	// last node may be one ply further back if the bot's move was never
+	// expanded.
+	depth := (len(b.Players())-1)*max(1, b.Rules.Stones) + 1
+	if found := c.lastNode.find(b.Hash, depth); found != nil {
+		found.Parent = nil
+		return found
 	}
 
 	return newRoot
@@ -102,8 +103,8 @@ func (c *Client) getNewRoot(b *tictactoe.Board) (newRoot *node) {
 func (c *Client) GetNextMove(ctx context.Context, rootBoard *tictactoe.Board, player tictactoe.Player) (int, error) {
 	c.lastMoveStats = nil
 
-	rootBoard.Turn = (rootBoard.N * rootBoard.N) - len(rootBoard.LegalMoves())
-	c.explorationParam = 0.9 + 0.6*math.Log(float64(rootBoard.N))
+	w, h := playArea(rootBoard)
+	c.explorationParam = 0.9 + 0.6*math.Log(math.Sqrt(float64(w*h)))
 
 	root := c.getNewRoot(rootBoard)
 
@@ -129,6 +130,24 @@ func (c *Client) GetNextMove(ctx context.Context, rootBoard *tictactoe.Board, pl
 		return move, nil
 	}
 
+	if len(tacticalMoves) == 0 {
+		if move, ok := forcingFork(rootBoard, player); ok {
+			for _, child := range root.Children {
+				if child.Move == move {
+					c.lastNode = child
+					break
+				}
+			}
+
+			c.lastMoveStats = &LastMoveStats{
+				BestMove:         move,
+				TacticalOverride: true,
+			}
+
+			return move, nil
+		}
+	}
+
 	results := make(chan threadResult, c.workers)
 
 	var wg sync.WaitGroup
@@ -259,13 +278,13 @@ mctsIteration:
 				return 0, fmt.Errorf("illegal move during selection: iteration %d, move %d, err %w", iterationsDone, n.Move, err)
 			}
 
-			current = -current
+			current = board.ToMove()
 		}
 
 		// Expansion
 		if n.canExpand() {
 			n = n.expand(board, current)
-			current = -current
+			current = board.ToMove()
 		}
 
 		// Simulation
@@ -280,26 +299,81 @@ mctsIteration:
 	return iterationsDone, nil
 }
 
+// forcingFork returns a move that leaves player two cells to win on while the
+// opponent has no row to complete first. With one stone per turn only one of
+// them can be blocked, so rules with more stones to a turn are left to the
+// search.
+func forcingFork(b *tictactoe.Board, player tictactoe.Player) (int, bool) {
+	r := b.Rules
+	if r.Set != tictactoe.Freestyle || r.Gravity || r.Misere || r.Ultimate || r.Capture > 0 || max(r.Stones, r.FirstStones) > 1 || r.Opening != tictactoe.NoOpening || len(b.Players()) != 2 {
+		return -1, false
+	}
+
+	if len(b.WinningCells(b.Opponent(player))) > 0 {
+		return -1, false
+	}
+
+	for _, idx := range b.ForkMoves(player) {
+		if b.ApplyMove(idx, player) != nil {
+			continue
+		}
+
+		wins := len(b.WinningCells(player))
+		b.UndoMove(idx)
+		if wins >= 2 {
+			return idx, true
+		}
+	}
+
+	return -1, false
+}
+
+// maxUnboundedPlayout is how many moves a playout on an unbounded board may
+// last before it is scored as a draw.
+const maxUnboundedPlayout = 300
+
+// unboundedMargin is added around the stones of an unbounded board when
+// sizing the search.
+const unboundedMargin = 4
+
+// playArea returns the width and height of the part of the board in play.
+func playArea(b *tictactoe.Board) (int, int) {
+	minX, minY, maxX, maxY := b.Bounds()
+	w, h := maxX-minX+1, maxY-minY+1
+	if b.Rules.Unbounded {
+		w += unboundedMargin
+		h += unboundedMargin
+	}
+
+	return w, h
+}
+
 func (c *Client) rollout(board *tictactoe.Board, player tictactoe.Player) tictactoe.Player {
 	current := player
 
-	for {
+	for plies := 0; ; plies++ {
 		if winner := board.CheckWinner(); winner != tictactoe.Empty {
 			return winner
 		}
 
-		if !board.AnyLegalMoves() {
+		if board.IsDead() {
+			return tictactoe.Empty
+		}
+
+		if board.Rules.Unbounded && plies >= maxUnboundedPlayout {
 			return tictactoe.Empty
 		}
 
-		moves := board.LegalMoves()
-		move := moves[rand.N(len(moves))]
+		move, ok := board.RandomLegalMove()
+		if !ok {
+			return tictactoe.Empty
+		}
 
 		err := board.ApplyMove(move, current)
 		if err != nil {
 			panic("Illegal move during rollout")
 		}
 
-		current = -current
+		current = board.ToMove()
 	}
 }
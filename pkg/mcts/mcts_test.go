@@ -0,0 +1,49 @@
+package mcts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+func TestBlockConnect6Four(t *testing.T) {
+	// X has four in a row on the top row of a Connect6 board and O, with both
+	// stones of its turn to play, has to keep X from making six.
+	four := []tictactoe.Move{{X: 3}, {X: 4}, {X: 5}, {X: 6}}
+	setup := tictactoe.Setup{
+		Stones: map[tictactoe.Player][]tictactoe.Move{
+			tictactoe.P1: four,
+			tictactoe.P2: {{X: 0, Y: 11}},
+		},
+		Turn:     1,
+		LastMove: &four[0],
+	}
+
+	for range 5 {
+		g, err := tictactoe.New(12, 12, 6, tictactoe.WithStonesPerTurn(2, 1), tictactoe.WithSetup(setup))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bot := New(1, 200)
+		bot.UpdateThinkTime(100 * time.Millisecond)
+
+		b := g.Board
+		for b.ToMove() == tictactoe.P2 {
+			move, err := bot.GetNextMove(context.Background(), b, tictactoe.P2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := g.PlayMove(move); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, threat := range b.Threats(tictactoe.P1) {
+			t.Fatalf("X can still make six on %s after O played %s", b.FormatMoves(threat.Gaps), b.FormatMoves(b.Moves()[len(b.Moves())-2:]))
+		}
+	}
+}
@@ -14,6 +14,7 @@ type node struct {
 
 	Move   int
 	Player tictactoe.Player
+	Hash   uint64
 
 	Wins   float64
 	Visits int
@@ -66,17 +67,23 @@ func (n *node) expand(board *tictactoe.Board, player tictactoe.Player) *node {
 	var centerMoves []int
 	var invalidMoves []int
 
-	center := float64(board.N) / 2.0
-	radius := math.Max(1, float64(board.N)/3)
+	minX, minY, maxX, maxY := board.Bounds()
+	w, h := playArea(board)
+	centerX := float64(minX+maxX+1) / 2.0
+	centerY := float64(minY+maxY+1) / 2.0
+	centerZ := float64(max(1, board.D)+1) / 2.0
+	radius := math.Max(1, float64(min(w, h))/3)
 
 	move := -1
 	for _, untriedMove := range n.UntriedMoves {
-		if board.Cells[untriedMove] != tictactoe.Empty {
+		if !board.IsLegal(untriedMove) {
 			invalidMoves = append(invalidMoves, untriedMove)
 			continue
 		}
 
-		if board.TacticalStone(untriedMove) {
+		// Under misère rules stones next to a row are as likely to lose as
+		// to matter, so they get no priority.
+		if !board.Rules.Misere && board.TacticalStone(untriedMove) {
 			tacticalMoves = append(tacticalMoves, untriedMove)
 			continue
 		}
@@ -85,10 +92,12 @@ func (n *node) expand(board *tictactoe.Board, player tictactoe.Player) *node {
 			nearbyMoves = append(nearbyMoves, untriedMove)
 		}
 
-		x := float64(untriedMove % board.N)
-		y := float64(untriedMove / board.N)
+		m := board.GetMove(untriedMove)
+		x := float64(m.X)
+		y := float64(m.Y)
+		z := float64(m.Z)
 
-		if math.Abs(x+1-center)+math.Abs(y+1-center) <= radius {
+		if math.Abs(x+1-centerX)+math.Abs(y+1-centerY)+math.Abs(z+1-centerZ) <= radius {
 			centerMoves = append(centerMoves, untriedMove)
 		}
 	}
@@ -121,6 +130,7 @@ func (n *node) expand(board *tictactoe.Board, player tictactoe.Player) *node {
 		Parent:       n,
 		Move:         move,
 		Player:       player,
+		Hash:         board.Hash,
 		UntriedMoves: board.LegalMoves(),
 		client:       n.client,
 	}
@@ -132,14 +142,30 @@ func (n *node) expand(board *tictactoe.Board, player tictactoe.Player) *node {
 const winValue = 1
 const drawValue = 0.6
 
+// rewards returns the max-n reward vector for a finished playout, indexed by
+// player.
+func rewards(winner tictactoe.Player) [tictactoe.MaxPlayers]float64 {
+	var r [tictactoe.MaxPlayers]float64
+	if winner == tictactoe.Empty {
+		for i := range r {
+			r[i] = drawValue
+		}
+
+		return r
+	}
+
+	r[winner.Idx()] = winValue
+	return r
+}
+
+// backpropagate credits every node on the path with the reward of the player
+// who made its move, so each node keeps its own player's score.
 func (n *node) backpropagate(winner tictactoe.Player) {
+	r := rewards(winner)
 	for n != nil {
 		n.Visits++
-		switch winner {
-		case tictactoe.Empty:
-			n.Wins += drawValue
-		case n.Player:
-			n.Wins += winValue
+		if i := n.Player.Idx(); i >= 0 {
+			n.Wins += r[i]
 		}
 
 		n = n.Parent
@@ -150,6 +176,7 @@ func (n *node) deepCopy(validMoves []int) *node {
 	newNode := &node{
 		Move:   n.Move,
 		Player: n.Player,
+		Hash:   n.Hash,
 		Wins:   n.Wins,
 		client: n.client,
 	}
@@ -173,3 +200,23 @@ func (n *node) deepCopy(validMoves []int) *node {
 
 	return newNode
 }
+
+// find looks for the node of the position with the given hash among n and its
+// descendants, at most depth moves below n.
+func (n *node) find(hash uint64, depth int) *node {
+	if n.Hash == hash {
+		return n
+	}
+
+	if depth == 0 {
+		return nil
+	}
+
+	for _, child := range n.Children {
+		if found := child.find(hash, depth-1); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package mcts
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+// balancedMargin is how close to even a position has to look for the bot to
+// prefer placing more stones over picking a color.
+const balancedMargin = 0.08
+
+const evenScore = 0.5
+
+// Evaluate searches the position and returns the estimated score of the best
+// move for player, between 0 and 1.
+func (c *Client) Evaluate(ctx context.Context, board *tictactoe.Board, player tictactoe.Player) (float64, error) {
+	_, err := c.GetNextMove(ctx, board, player)
+	if err != nil {
+		return 0, err
+	}
+
+	stats := c.lastMoveStats
+	if stats == nil {
+		return winValue, nil
+	}
+
+	if stats.MoveVisits == 0 {
+		return drawValue, nil
+	}
+
+	return stats.MoveWins / float64(stats.MoveVisits), nil
+}
+
+// ChooseOpening decides which color to take, or whether to place more stones,
+// when the game is waiting on a choice from the bot.
+func (c *Client) ChooseOpening(ctx context.Context, g *tictactoe.Game) (tictactoe.Choice, error) {
+	toMove := g.Board.ToMove()
+	score, err := c.Evaluate(ctx, g.Board.Clone(), toMove)
+	if err != nil {
+		return 0, err
+	}
+
+	choices := g.Choices()
+	if slices.Contains(choices, tictactoe.ChoosePlaceTwo) && math.Abs(score-evenScore) < balancedMargin {
+		return tictactoe.ChoosePlaceTwo, nil
+	}
+
+	toMoveGood := score >= evenScore
+	if (toMove == tictactoe.P1) == toMoveGood {
+		return tictactoe.ChooseP1, nil
+	}
+
+	return tictactoe.ChooseP2, nil
+}
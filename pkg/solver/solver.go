@@ -0,0 +1,413 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+// MaxCells is the largest board the solver searches.
+const MaxCells = 25
+
+var ErrTooLarge = errors.New("board is too large to solve")
+
+type Result int8
+
+const (
+	Loss Result = iota - 1
+	Draw
+	Win
+)
+
+func (r Result) String() string {
+	switch r {
+	case Loss:
+		return "loss"
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	}
+
+	return fmt.Sprintf("Result(%d)", r)
+}
+
+// Value is the outcome of a position with perfect play, for the player to
+// move. Plies is how many plies it takes to win or lose, and zero for draws.
+type Value struct {
+	Result Result
+	Plies  int
+}
+
+func (v Value) String() string {
+	return fmt.Sprintf("%s in %d", v.Result, v.Plies)
+}
+
+// mate is the score of winning right away. Wins further away score less, so
+// the search prefers quick wins and slow losses.
+const mate = math.MaxInt32
+
+type bound int8
+
+const (
+	exact bound = iota
+	lower
+	upper
+)
+
+type entry struct {
+	score int
+	bound bound
+}
+
+// Client plays perfectly by searching positions to the end with alpha-beta.
+// Boards with more than MaxCells cells, and positions it can not solve within
+// the think time, are handed to the fallback bot.
+type Client struct {
+	fallback  *mcts.Client
+	thinkTime time.Duration
+
+	table map[uint64]entry
+	order []int
+	nodes int
+
+	lastMoveStats *mcts.LastMoveStats
+}
+
+// New returns a solver that leaves boards too large to solve to fallback,
+// which may be nil.
+func New(fallback *mcts.Client) *Client {
+	return &Client{
+		fallback:  fallback,
+		thinkTime: time.Second,
+	}
+}
+
+// UpdateThinkTime sets how long the solver searches before giving up on the
+// position, which is also how long the fallback bot thinks.
+func (c *Client) UpdateThinkTime(t time.Duration) {
+	c.thinkTime = t
+	if c.fallback != nil {
+		c.fallback.UpdateThinkTime(t)
+	}
+}
+
+func (c *Client) Stats() *mcts.LastMoveStats {
+	return c.lastMoveStats
+}
+
+// GetNextMove plays one of the optimal moves, picked at random.
+func (c *Client) GetNextMove(ctx context.Context, board *tictactoe.Board, player tictactoe.Player) (int, error) {
+	c.lastMoveStats = nil
+	if player != board.ToMove() {
+		return 0, fmt.Errorf("%s is not to move", player.Mark())
+	}
+
+	t := time.Now()
+	solveCtx, cancel := context.WithTimeout(ctx, c.thinkTime)
+	defer cancel()
+
+	value, moves, err := c.Solve(solveCtx, board)
+	if c.handOver(ctx, err) {
+		move, err := c.fallback.GetNextMove(ctx, board, player)
+		c.lastMoveStats = c.fallback.Stats()
+		return move, err
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(moves) == 0 {
+		return 0, errors.New("no legal moves")
+	}
+
+	move := moves[rand.N(len(moves))]
+	c.lastMoveStats = &mcts.LastMoveStats{
+		RealThinkTime:   time.Since(t),
+		ActualThinkTime: time.Since(t),
+		NumIterations:   c.nodes,
+		BestMove:        move,
+		MoveVisits:      1,
+		MoveWins:        float64(value.Result+1) / 2,
+	}
+
+	return move, nil
+}
+
+// ChooseOpening takes the color that wins with perfect play, or stays with
+// the player to move if neither does.
+func (c *Client) ChooseOpening(ctx context.Context, g *tictactoe.Game) (tictactoe.Choice, error) {
+	evalCtx, cancel := context.WithTimeout(ctx, c.thinkTime)
+	defer cancel()
+
+	value, err := c.Evaluate(evalCtx, g.Board)
+	if c.handOver(ctx, err) {
+		return c.fallback.ChooseOpening(ctx, g)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	toMoveGood := value.Result != Loss
+	if (g.Board.ToMove() == tictactoe.P1) == toMoveGood {
+		return tictactoe.ChooseP1, nil
+	}
+
+	return tictactoe.ChooseP2, nil
+}
+
+// handOver reports whether the fallback bot should take over after the solver
+// failed with err, which is when the board is too large or the think time ran
+// out.
+func (c *Client) handOver(ctx context.Context, err error) bool {
+	if c.fallback == nil {
+		return false
+	}
+
+	return errors.Is(err, ErrTooLarge) || ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// Solvable reports whether the solver can search the board.
+func Solvable(b *tictactoe.Board) bool {
+	return !b.Rules.Unbounded && len(b.Cells) <= MaxCells && len(b.Players()) == 2
+}
+
+// Evaluate returns the value of the position for the player to move.
+func (c *Client) Evaluate(ctx context.Context, board *tictactoe.Board) (Value, error) {
+	if !Solvable(board) {
+		return Value{}, ErrTooLarge
+	}
+
+	c.reset(board)
+	score, err := c.search(ctx, board.Clone(), 0, -mate, mate)
+	if err != nil {
+		return Value{}, err
+	}
+
+	return toValue(score), nil
+}
+
+// Solve returns the value of the position for the player to move and every
+// move that keeps it.
+func (c *Client) Solve(ctx context.Context, board *tictactoe.Board) (Value, []int, error) {
+	if !Solvable(board) {
+		return Value{}, nil, ErrTooLarge
+	}
+
+	c.reset(board)
+	b := board.Clone()
+	player := b.ToMove()
+
+	best, err := c.search(ctx, b, 0, -mate, mate)
+	if err != nil {
+		return Value{}, nil, err
+	}
+
+	// A window around the best score is enough to tell which moves reach it.
+	var moves []int
+	for _, move := range c.order {
+		if !b.IsLegal(move) {
+			continue
+		}
+
+		score, err := c.child(ctx, b, move, player, 0, best-1, best+1)
+		if err != nil {
+			return Value{}, nil, err
+		}
+
+		if score == best {
+			moves = append(moves, move)
+		}
+	}
+
+	return toValue(best), moves, nil
+}
+
+func (c *Client) reset(b *tictactoe.Board) {
+	// Zobrist keys differ between games, so the table is only good for one.
+	c.table = map[uint64]entry{}
+	c.nodes = 0
+
+	// Moves near the center are tried first.
+	c.order = make([]int, len(b.Cells))
+	for i := range c.order {
+		c.order[i] = i
+	}
+
+	distance := func(idx int) float64 {
+		m := b.GetMove(idx)
+		return math.Abs(float64(2*m.X-b.W+1)) + math.Abs(float64(2*m.Y-b.H+1))
+	}
+
+	slices.SortStableFunc(c.order, func(a, b int) int {
+		return int(distance(a) - distance(b))
+	})
+}
+
+// orderedMoves returns the moves worth searching, most promising first.
+func (c *Client) orderedMoves(b *tictactoe.Board) []int {
+	// With one stone per turn and no captures a win has to be taken and a
+	// row the opponent is about to complete has to be blocked.
+	r := b.Rules
+	if max(r.Stones, r.FirstStones) <= 1 && r.Capture == 0 && !r.Misere {
+		if moves, _ := b.TacticalMoves(b.ToMove()); len(moves) > 0 {
+			return moves
+		}
+	}
+
+	var tactical, rest []int
+	for _, idx := range c.order {
+		if !b.IsLegal(idx) {
+			continue
+		}
+
+		if b.TacticalStone(idx) {
+			tactical = append(tactical, idx)
+		} else {
+			rest = append(rest, idx)
+		}
+	}
+
+	return append(tactical, rest...)
+}
+
+// child plays move and returns the score of the result for player.
+func (c *Client) child(ctx context.Context, b *tictactoe.Board, move int, player tictactoe.Player, ply, alpha, beta int) (int, error) {
+	if err := b.ApplyMove(move, player); err != nil {
+		return 0, err
+	}
+	defer b.UndoMove(move)
+
+	// Some rules give a player several stones in a row.
+	if b.ToMove() == player {
+		return c.search(ctx, b, ply+1, alpha, beta)
+	}
+
+	score, err := c.search(ctx, b, ply+1, -beta, -alpha)
+	return -score, err
+}
+
+// search returns the score of the position for the player to move. Wins score
+// mate less the ply they happen on and losses the negative of that.
+func (c *Client) search(ctx context.Context, b *tictactoe.Board, ply, alpha, beta int) (int, error) {
+	c.nodes++
+	if c.nodes%4096 == 0 {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	player := b.ToMove()
+	if winner := b.CheckWinner(); winner != tictactoe.Empty {
+		if winner == player {
+			return mate - ply, nil
+		}
+
+		return -(mate - ply), nil
+	}
+
+	if !b.AnyLegalMoves() || b.IsDead() {
+		return 0, nil
+	}
+
+	key := c.key(b)
+	if e, ok := c.table[key]; ok {
+		score := fromTable(e.score, ply)
+		switch {
+		case e.bound == exact:
+			return score, nil
+		case e.bound == lower && score >= beta:
+			return score, nil
+		case e.bound == upper && score <= alpha:
+			return score, nil
+		}
+	}
+
+	origAlpha := alpha
+	best := -mate - 1
+	for _, move := range c.orderedMoves(b) {
+		score, err := c.child(ctx, b, move, player, ply, alpha, beta)
+		if err != nil {
+			return 0, err
+		}
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+
+	e := entry{score: toTable(best, ply), bound: exact}
+	switch {
+	case best <= origAlpha:
+		e.bound = upper
+	case best >= beta:
+		e.bound = lower
+	}
+
+	c.table[key] = e
+	return best, nil
+}
+
+// key identifies the position up to symmetry, along with what the stones
+// alone do not tell: whose turn it is and how many pairs were captured.
+func (c *Client) key(b *tictactoe.Board) uint64 {
+	h, _ := b.CanonicalHash()
+	h ^= mix(uint64(b.Turn))
+	if b.Rules.Capture > 0 {
+		h ^= mix(uint64(b.Captures(tictactoe.P1))<<32 | uint64(b.Captures(tictactoe.P2))<<48)
+	}
+
+	return h
+}
+
+func mix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// The table stores wins and losses counted from the position rather than
+// from the root, so they stay right wherever the position comes up.
+func toTable(score, ply int) int {
+	switch {
+	case score > 0:
+		return score + ply
+	case score < 0:
+		return score - ply
+	}
+
+	return 0
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score > 0:
+		return score - ply
+	case score < 0:
+		return score + ply
+	}
+
+	return 0
+}
+
+func toValue(score int) Value {
+	switch {
+	case score > 0:
+		return Value{Result: Win, Plies: mate - score}
+	case score < 0:
+		return Value{Result: Loss, Plies: mate + score}
+	}
+
+	return Value{Result: Draw}
+}
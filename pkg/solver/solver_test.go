@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		moves   []int
+		want    Value
+		best    []int
+	}{
+		{"3x3 is a draw", 3, 3, 3, nil, Value{Result: Draw}, nil},
+		{"4x4 with rows of 3 is a win", 4, 4, 3, nil, Value{Result: Win, Plies: 5}, nil},
+		{"win in one", 3, 3, 3, []int{0, 3, 1, 4}, Value{Result: Win, Plies: 1}, []int{2}},
+		{"lost to a fork", 3, 3, 3, []int{0, 1, 4}, Value{Result: Loss, Plies: 4}, []int{8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tictactoe.New(tt.w, tt.h, tt.k)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, idx := range tt.moves {
+				if err := g.PlayMove(idx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c := New(nil)
+			value, moves, err := c.Solve(context.Background(), g.Board)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != tt.want {
+				t.Errorf("value %s, expected %s", value, tt.want)
+			}
+
+			if tt.best != nil && !slices.Equal(moves, tt.best) {
+				t.Errorf("best moves %v, expected %v", moves, tt.best)
+			}
+		})
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	g, err := tictactoe.New(7, 7, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(nil)
+	if _, err := c.Evaluate(context.Background(), g.Board); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Evaluate on 7x7: %v, expected %v", err, ErrTooLarge)
+	}
+
+	if _, err := c.GetNextMove(context.Background(), g.Board, g.Board.ToMove()); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("GetNextMove on 7x7 without a fallback: %v, expected %v", err, ErrTooLarge)
+	}
+}
@@ -0,0 +1,255 @@
+package tictactoe
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// bitboardWords is how many words a bitboard spans. Boards are laid out row by
+// row with one spare column, so shifting a row never spills into the next, and
+// everything up to 19×19 fits.
+const bitboardWords = 6
+
+const maxBitboardBits = bitboardWords * 64
+
+type bitboard [bitboardWords]uint64
+
+func (bb *bitboard) set(pos int) {
+	bb[pos>>6] |= 1 << (pos & 63)
+}
+
+func (bb *bitboard) clear(pos int) {
+	bb[pos>>6] &^= 1 << (pos & 63)
+}
+
+// shr moves every bit n positions towards zero.
+func (bb *bitboard) shr(n int) bitboard {
+	var r bitboard
+	w, s := n>>6, uint(n&63)
+	for i := 0; i+w < bitboardWords; i++ {
+		r[i] = bb[i+w] >> s
+		if s != 0 && i+w+1 < bitboardWords {
+			r[i] |= bb[i+w+1] << (64 - s)
+		}
+	}
+
+	return r
+}
+
+func (bb *bitboard) and(o *bitboard) {
+	for i := range bb {
+		bb[i] &= o[i]
+	}
+}
+
+func (bb *bitboard) empty() bool {
+	for _, w := range bb {
+		if w != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bb *bitboard) count() int {
+	n := 0
+	for _, w := range bb {
+		n += bits.OnesCount64(w)
+	}
+
+	return n
+}
+
+// nth returns the position of the nth set bit.
+func (bb *bitboard) nth(n int) int {
+	for i, w := range bb {
+		c := bits.OnesCount64(w)
+		if n >= c {
+			n -= c
+			continue
+		}
+
+		for range n {
+			w &= w - 1
+		}
+
+		return i<<6 + bits.TrailingZeros64(w)
+	}
+
+	return -1
+}
+
+// bitboards holds the stones of a board one bitboard per player, kept in step
+// with Cells by ApplyMove and UndoMove.
+type bitboards struct {
+	stride int
+	stones [MaxPlayers]bitboard
+	occ    bitboard
+
+	// cells has a bit for every cell on the board, bottom for the bottom row.
+	cells  bitboard
+	bottom bitboard
+
+	// subs has the cells of each sub-board of an ultimate board.
+	subs [subSize * subSize]bitboard
+}
+
+func (b *Board) canUseBitboards() bool {
+	return !b.Rules.Unbounded && !b.Rules.Wrap && b.D <= 1 && (b.W+1)*b.H <= maxBitboardBits
+}
+
+func (b *Board) initBitboards() {
+	if !b.canUseBitboards() {
+		b.bits = nil
+		return
+	}
+
+	bb := &bitboards{stride: b.W + 1}
+	for idx, p := range b.Cells {
+		pos := bb.pos(idx, b.W)
+		bb.cells.set(pos)
+		if idx/b.W == b.H-1 {
+			bb.bottom.set(pos)
+		}
+
+		if b.Rules.Ultimate {
+			bb.subs[b.SubBoard(idx)].set(pos)
+		}
+
+		if p.IsStone() {
+			bb.stones[p.Idx()].set(pos)
+		}
+
+		if p != Empty {
+			bb.occ.set(pos)
+		}
+	}
+
+	b.bits = bb
+}
+
+func (bb *bitboards) pos(idx, w int) int {
+	return idx + idx/w
+}
+
+func (bb *bitboards) idx(pos int) int {
+	return pos - pos/bb.stride
+}
+
+func (bb *bitboards) place(idx, w int, p Player) {
+	pos := bb.pos(idx, w)
+	bb.stones[p.Idx()].set(pos)
+	bb.occ.set(pos)
+}
+
+func (bb *bitboards) remove(idx, w int, p Player) {
+	pos := bb.pos(idx, w)
+	bb.stones[p.Idx()].clear(pos)
+	bb.occ.clear(pos)
+}
+
+// legalBits returns the cells a stone may be placed on, or false if the rules need
+// more than the stones to decide that.
+func (b *Board) legalBits() (bitboard, bool) {
+	if b.bits == nil || b.Rules.Set == Renju {
+		return bitboard{}, false
+	}
+
+	bb := b.bits
+	legal := bb.cells
+	for i := range legal {
+		legal[i] &^= bb.occ[i]
+	}
+
+	if b.Rules.Gravity {
+		supported := bb.occ.shr(bb.stride)
+		for i := range supported {
+			supported[i] |= bb.bottom[i]
+		}
+
+		legal.and(&supported)
+	}
+
+	if b.Rules.Ultimate {
+		mask := b.ultimateMask()
+		legal.and(&mask)
+	}
+
+	return legal, true
+}
+
+// kInRow reports whether p has k or more stones in a row. The stones are
+// shifted along each direction and ANDed together, so a bit is left on every
+// cell that starts a row.
+func (bb *bitboards) kInRow(p Player, k int) bool {
+	stones := &bb.stones[p.Idx()]
+	s := bb.stride
+	for _, shift := range [...]int{1, s, s + 1, s - 1} {
+		acc := *stones
+		for i := 1; i < k && !acc.empty(); i++ {
+			shifted := stones.shr(shift * i)
+			acc.and(&shifted)
+		}
+
+		if !acc.empty() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LegalMoveCount returns how many moves the player to move has.
+func (b *Board) LegalMoveCount() int {
+	if legal, ok := b.legalBits(); ok {
+		return legal.count()
+	}
+
+	return len(b.LegalMoves())
+}
+
+// RandomLegalMove picks one of the legal moves uniformly at random without
+// building the list of moves.
+func (b *Board) RandomLegalMove() (int, bool) {
+	if legal, ok := b.legalBits(); ok {
+		n := legal.count()
+		if n == 0 {
+			return -1, false
+		}
+
+		return b.bits.idx(legal.nth(rand.N(n))), true
+	}
+
+	moves := b.LegalMoves()
+	if len(moves) == 0 {
+		return -1, false
+	}
+
+	return moves[rand.N(len(moves))], true
+}
+
+// ForEachLegalMove calls fn with every legal move until fn returns false.
+func (b *Board) ForEachLegalMove(fn func(idx int) bool) {
+	legal, ok := b.legalBits()
+	if !ok {
+		for _, idx := range b.LegalMoves() {
+			if !fn(idx) {
+				return
+			}
+		}
+
+		return
+	}
+
+	for i, w := range legal {
+		for w != 0 {
+			pos := i<<6 + bits.TrailingZeros64(w)
+			if !fn(b.bits.idx(pos)) {
+				return
+			}
+
+			w &= w - 1
+		}
+	}
+}
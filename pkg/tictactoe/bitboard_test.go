@@ -0,0 +1,220 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func playout(b *Board, bitboards bool) {
+	for {
+		if winner := b.CheckWinner(); winner != Empty {
+			return
+		}
+
+		if bitboards {
+			move, ok := b.RandomLegalMove()
+			if !ok {
+				return
+			}
+
+			b.ApplyMove(move, b.ToMove())
+			continue
+		}
+
+		if !b.AnyLegalMoves() {
+			return
+		}
+
+		moves := b.LegalMoves()
+		b.ApplyMove(moves[rand.N(len(moves))], b.ToMove())
+	}
+}
+
+// naiveKInRow scans every stone of p for a row of K or more starting on it,
+// or of exactly K if exact is set.
+func naiveKInRow(b *Board, p Player, exact bool) bool {
+	for idx := range b.Cells {
+		if b.At(idx) != p {
+			continue
+		}
+
+		for _, d := range b.lines() {
+			back, fwd := b.run(idx, d, p)
+			if back == 0 && (fwd+1 == b.K || !exact && fwd+1 > b.K) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func TestWinDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		w, h, k   int
+		opts      []Option
+		bitboards bool
+	}{
+		{"freestyle", 7, 7, 4, nil, true},
+		{"rectangular", 9, 5, 5, nil, true},
+		{"largest bitboard", 19, 19, 5, nil, true},
+		{"exact", 7, 7, 4, []Option{WithRuleSet(Exact)}, true},
+		{"gravity", 7, 6, 4, []Option{WithGravity()}, true},
+		{"torus", 5, 5, 4, []Option{WithWrap()}, false},
+		{"three players", 7, 7, 4, []Option{WithPlayers(3)}, true},
+		{"blocked", 7, 7, 4, []Option{WithBlocked(Move{X: 3, Y: 3}, Move{X: 0, Y: 6})}, true},
+		{"cube", 4, 4, 4, []Option{WithDepth(4)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Games are played on past rows to the last cell, so rows are
+				// made and joined into longer ones.
+				b := g.Board
+				if (b.bits != nil) != tt.bitboards {
+					t.Fatalf("board has bitboards %t, expected %t", b.bits != nil, tt.bitboards)
+				}
+
+				for {
+					move, ok := b.RandomLegalMove()
+					if !ok {
+						break
+					}
+
+					if err := b.ApplyMove(move, b.ToMove()); err != nil {
+						t.Fatal(err)
+					}
+
+					for _, p := range b.Players() {
+						if got, want := b.KInRow(p), naiveKInRow(b, p, !b.wins(b.K+1, p)); got != want {
+							t.Fatalf("KInRow(%s) = %t, expected %t after %s", p.Mark(), got, want, b.FormatMoves(b.Moves()))
+						}
+
+						// Bitboards and window counts find overlines too.
+						want := naiveKInRow(b, p, false)
+
+						if b.bits != nil {
+							if got := b.bits.kInRow(p, b.K); got != want {
+								t.Fatalf("bitboard row of %s %t, expected %t after %s", p.Mark(), got, want, b.FormatMoves(b.Moves()))
+							}
+						}
+
+						if got := b.counts.full[p.Idx()] > 0; got != want {
+							t.Fatalf("full windows of %s %t, expected %t after %s", p.Mark(), got, want, b.FormatMoves(b.Moves()))
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitboardLegalMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		opts    []Option
+	}{
+		{"freestyle", 7, 7, 4, nil},
+		{"gravity", 7, 6, 4, []Option{WithGravity()}},
+		{"blocked", 7, 7, 4, []Option{WithBlocked(Move{X: 3, Y: 3})}},
+		{"ultimate", 9, 9, 3, []Option{WithUltimate()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			if b.bits == nil {
+				t.Fatal("board has no bitboards")
+			}
+
+			for {
+				scan := b.Clone()
+				scan.bits = nil
+
+				want := scan.LegalMoves()
+				got := b.LegalMoves()
+				slices.Sort(want)
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Fatalf("legal moves %v, expected %v after %s", got, want, b.FormatMoves(b.Moves()))
+				}
+
+				if n := b.LegalMoveCount(); n != len(want) {
+					t.Fatalf("%d legal moves counted, expected %d", n, len(want))
+				}
+
+				move, ok := b.RandomLegalMove()
+				if ok != (len(want) > 0) || ok && !slices.Contains(want, move) {
+					t.Fatalf("random move %d is not one of %v", move, want)
+				}
+
+				if !ok || b.CheckWinner() != Empty {
+					return
+				}
+
+				if err := b.ApplyMove(move, b.ToMove()); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPlayout(b *testing.B) {
+	sizes := []struct{ w, h, k int }{{7, 7, 4}, {15, 15, 5}}
+	for _, size := range sizes {
+		for _, bitboards := range []bool{true, false} {
+			name := fmt.Sprintf("%dx%dk%d/bitboard=%t", size.w, size.h, size.k, bitboards)
+			b.Run(name, func(b *testing.B) {
+				g, err := New(size.w, size.h, size.k)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if !bitboards {
+					g.Board.bits = nil
+				}
+
+				b.ReportAllocs()
+				for b.Loop() {
+					playout(g.Board.Clone(), bitboards)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkKInRow(b *testing.B) {
+	for _, bitboards := range []bool{true, false} {
+		b.Run(fmt.Sprintf("bitboard=%t", bitboards), func(b *testing.B) {
+			g, err := New(15, 15, 5)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			playout(g.Board, true)
+			if !bitboards {
+				g.Board.bits = nil
+			}
+
+			for b.Loop() {
+				g.Board.KInRow(P1)
+			}
+		})
+	}
+}
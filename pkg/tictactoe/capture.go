@@ -0,0 +1,83 @@
+package tictactoe
+
+// WithCaptures lets a stone capture exactly two enemy stones it flanks
+// together with another of its own stones, as in Pente. Capturing the given
+// number of pairs wins the game.
+func WithCaptures(pairs int) Option {
+	return func(b *Board) {
+		b.Rules.Capture = pairs
+	}
+}
+
+// Stone is a stone of Player standing on Idx.
+type Stone struct {
+	Player Player
+	Idx    int
+}
+
+// Captures returns how many pairs p has captured.
+func (b *Board) Captures(p Player) int {
+	if !p.IsStone() {
+		return 0
+	}
+
+	return b.captures[p.Idx()]
+}
+
+// capture removes every pair p flanks by playing idx and returns the removed
+// stones.
+func (b *Board) capture(idx int, p Player) []Stone {
+	var captured []Stone
+	for _, d := range b.lines() {
+		for _, dir := range []int{1, -1} {
+			first, ok1 := b.step(idx, d, dir)
+			second, ok2 := b.step(idx, d, 2*dir)
+			end, ok3 := b.step(idx, d, 3*dir)
+			if !ok1 || !ok2 || !ok3 || end == idx {
+				continue
+			}
+
+			enemy := b.At(first)
+			if !enemy.IsStone() || enemy == p || b.At(second) != enemy || b.At(end) != p {
+				continue
+			}
+
+			b.lift(first, enemy)
+			b.lift(second, enemy)
+			captured = append(captured, Stone{Player: enemy, Idx: first}, Stone{Player: enemy, Idx: second})
+		}
+	}
+
+	b.captures[p.Idx()] += len(captured) / 2
+	return captured
+}
+
+// captureWinner returns the player who has captured enough pairs to win.
+func (b *Board) captureWinner() Player {
+	if b.Rules.Capture == 0 {
+		return Empty
+	}
+
+	last, ok := b.LastPly()
+	if ok && b.Captures(last.Player) >= b.Rules.Capture {
+		return last.Player
+	}
+
+	return Empty
+}
+
+// countCaptures recounts the captured pairs from the history.
+func (b *Board) countCaptures() {
+	b.captures = [MaxPlayers]int{}
+	if b.Setup != nil {
+		for i, n := range b.Setup.Captures {
+			b.captures[b.Players()[i].Idx()] = n
+		}
+	}
+
+	for _, ply := range b.History {
+		if len(ply.Captured) > 0 {
+			b.captures[ply.Player.Idx()] += len(ply.Captured) / 2
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tictactoe
+
+// spaceDirections are the 13 line directions of a 3D board: the 4 within a
+// layer and the 9 that go up through the layers.
+var spaceDirections = []Dir{
+	{1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, -1, 0},
+	{0, 0, 1}, {1, 0, 1}, {-1, 0, 1}, {0, 1, 1}, {0, -1, 1},
+	{1, 1, 1}, {1, -1, 1}, {-1, 1, 1}, {-1, -1, 1},
+}
+
+// WithDepth stacks d layers of W×H cells into a 3D board, like 4×4×4 Qubic.
+// Lines run in all 13 directions through the cube.
+func WithDepth(d int) Option {
+	return func(b *Board) {
+		b.D = d
+	}
+}
+
+// depth returns the number of layers, which is one for flat boards.
+func (b *Board) depth() int {
+	return max(1, b.D)
+}
+
+// Layer returns which layer idx is on.
+func (b *Board) Layer(idx int) int {
+	if b.D <= 1 {
+		return 0
+	}
+
+	return b.zList[idx]
+}
+
+// GetIdx3 returns the cell at x, y on layer z.
+func (b *Board) GetIdx3(x, y, z int) int {
+	return b.GetIdx(x, y) + z*b.W*b.H
+}
+
+// InBounds3 reports whether x, y on layer z is on the board.
+func (b *Board) InBounds3(x, y, z int) bool {
+	return b.InBounds(x, y) && z >= 0 && z < b.depth()
+}
+
+// moveIdx returns the cell of m.
+func (b *Board) moveIdx(m Move) int {
+	return b.GetIdx3(m.X, m.Y, m.Z)
+}
+
+// lines returns the directions lines run in on the board.
+func (b *Board) lines() []Dir {
+	if b.D > 1 {
+		return spaceDirections
+	}
+
+	return directions
+}
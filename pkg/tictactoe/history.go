@@ -0,0 +1,104 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errNothingToUndo = errors.New("nothing to undo")
+var errNothingToRedo = errors.New("nothing to redo")
+
+// Ply is a single stone placed on the board. Hash is the board hash after the
+// stone was placed.
+type Ply struct {
+	Player Player
+	Idx    int
+	Hash   uint64
+
+	// Captured holds the stones the ply removed from the board.
+	Captured []Stone `json:",omitempty"`
+}
+
+// LastPly returns the most recent ply, if any.
+func (b *Board) LastPly() (Ply, bool) {
+	if len(b.History) == 0 {
+		return Ply{}, false
+	}
+
+	return b.History[len(b.History)-1], true
+}
+
+// undoFloor returns the number of stones that can not be taken back without
+// also taking back an opening choice.
+func (g *Game) undoFloor() int {
+	switch {
+	case g.Board.Rules.Opening == NoOpening:
+		return 0
+	case g.Phase == PhasePlay:
+		return g.openingStones()
+	case g.Extended:
+		return 3
+	}
+
+	return 0
+}
+
+// Undo takes back the last ply and keeps it so it can be redone.
+func (g *Game) Undo() error {
+	if g.ended != nil {
+		return errGameOver
+	}
+
+	ply, ok := g.Board.LastPly()
+	if !ok || g.Board.Turn <= g.undoFloor() {
+		return errNothingToUndo
+	}
+
+	if err := g.Board.UndoMove(ply.Idx); err != nil {
+		return err
+	}
+
+	if g.Phase == PhaseChoose {
+		g.Phase = PhaseOpening
+	}
+
+	g.Undone = append(g.Undone, ply)
+	return nil
+}
+
+// Redo plays the last undone ply again. If the position has changed so that the
+// ply no longer leads to the position it was undone from, the redo stack is
+// dropped.
+func (g *Game) Redo() error {
+	if len(g.Undone) == 0 {
+		return errNothingToRedo
+	}
+
+	ply := g.Undone[len(g.Undone)-1]
+	undone := g.Undone[:len(g.Undone)-1]
+
+	if ply.Player != g.Board.ToMove() {
+		g.Undone = nil
+		return fmt.Errorf("redo %d: %s is not to move: %w", ply.Idx, ply.Player.Mark(), errIllegalMove)
+	}
+
+	phase := g.Phase
+	if err := g.play(ply.Idx); err != nil {
+		g.Undone = nil
+		return err
+	}
+
+	if g.Board.Hash != ply.Hash {
+		// The ply is taken back so the board is left as it was.
+		if err := g.Board.UndoMove(ply.Idx); err != nil {
+			return err
+		}
+
+		g.Phase = phase
+		g.Undone = nil
+		return fmt.Errorf("redo %d: position does not match the undone one", ply.Idx)
+	}
+
+	g.Undone = undone
+	return nil
+}
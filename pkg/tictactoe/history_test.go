@@ -0,0 +1,201 @@
+package tictactoe
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRedoMismatch(t *testing.T) {
+	g, err := New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, idx := range []int{4, 0} {
+		if err := g.PlayMove(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	cells, hash, turn, last := slices.Clone(g.Board.Cells), g.Board.Hash, g.Board.Turn, g.Board.LastMove
+	g.Undone[0].Hash ^= 1
+
+	if err := g.Redo(); err == nil {
+		t.Fatal("redo of a ply with the wrong hash succeeded")
+	}
+
+	b := g.Board
+	if !slices.Equal(b.Cells, cells) || b.Hash != hash || b.Turn != turn || b.LastMove != last || len(b.History) != turn {
+		t.Errorf("failed redo left the board at turn %d, expected it unchanged at turn %d", b.Turn, turn)
+	}
+
+	if len(g.Undone) != 0 {
+		t.Errorf("%d plies left to redo, expected the stack to be dropped", len(g.Undone))
+	}
+}
+
+// snapshot is what undo and redo have to bring back after each ply.
+type snapshot struct {
+	cells    []Player
+	hash     uint64
+	turn     int
+	toMove   Player
+	captures [2]int
+}
+
+func snap(b *Board) snapshot {
+	return snapshot{
+		cells:    slices.Clone(b.Cells),
+		hash:     b.Hash,
+		turn:     b.Turn,
+		toMove:   b.ToMove(),
+		captures: [2]int{b.Captures(P1), b.Captures(P2)},
+	}
+}
+
+func (s snapshot) equal(o snapshot) bool {
+	return slices.Equal(s.cells, o.cells) && s.hash == o.hash && s.turn == o.turn && s.toMove == o.toMove && s.captures == o.captures
+}
+
+func TestUndoRedo(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		opts    []Option
+		moves   []Move
+	}{
+		{"single stones", 3, 3, 3, nil, []Move{{X: 1, Y: 1}, {X: 0, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 2}}},
+		{"connect6", 9, 9, 6, []Option{WithStonesPerTurn(2, 1)}, []Move{{X: 4, Y: 4}, {X: 3, Y: 3}, {X: 5, Y: 5}, {X: 4, Y: 3}, {X: 3, Y: 4}, {X: 6, Y: 6}}},
+		{"captures", 7, 7, 5, []Option{WithCaptures(5)}, []Move{{X: 3, Y: 3}, {X: 4, Y: 3}, {X: 0, Y: 0}, {X: 5, Y: 3}, {X: 6, Y: 3}, {X: 4, Y: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			snaps := []snapshot{snap(b)}
+			for _, m := range tt.moves {
+				if err := g.PlayMove(b.moveIdx(m)); err != nil {
+					t.Fatal(err)
+				}
+
+				snaps = append(snaps, snap(b))
+			}
+
+			for i := len(tt.moves) - 1; i >= 0; i-- {
+				if err := g.Undo(); err != nil {
+					t.Fatalf("undo to turn %d: %v", i, err)
+				}
+
+				if !snap(b).equal(snaps[i]) {
+					t.Fatalf("undo to turn %d left the board at turn %d with %s to move, expected it as it was", i, b.Turn, b.ToMove().Mark())
+				}
+			}
+
+			if err := g.Undo(); err == nil {
+				t.Error("undo of the empty board succeeded")
+			}
+
+			for i := 1; i <= len(tt.moves); i++ {
+				if err := g.Redo(); err != nil {
+					t.Fatalf("redo to turn %d: %v", i, err)
+				}
+
+				if !snap(b).equal(snaps[i]) {
+					t.Fatalf("redo to turn %d left the board at turn %d with %s to move, expected it as it was", i, b.Turn, b.ToMove().Mark())
+				}
+			}
+
+			if err := g.Redo(); err == nil {
+				t.Error("redo with nothing undone succeeded")
+			}
+		})
+	}
+}
+
+func TestMoveClearsRedo(t *testing.T) {
+	g, err := New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, idx := range []int{4, 0, 8} {
+		if err := g.PlayMove(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for range 2 {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The undone plies were 0 and 8, so playing 0 again still drops them.
+	if err := g.PlayMove(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(g.Undone) != 0 {
+		t.Errorf("%d plies left to redo after a new move, expected none", len(g.Undone))
+	}
+
+	if err := g.Redo(); err == nil {
+		t.Error("redo after a new move succeeded")
+	}
+}
+
+func TestUndoneSaved(t *testing.T) {
+	g, err := New(7, 7, 5, WithCaptures(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last ply captures the pair of O stones.
+	for _, m := range []Move{{X: 3, Y: 3}, {X: 4, Y: 3}, {X: 0, Y: 0}, {X: 5, Y: 3}, {X: 6, Y: 3}} {
+		if err := g.PlayMove(g.Board.moveIdx(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := snap(g.Board)
+	for range 3 {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lg, err := LoadGame(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.EqualFunc(lg.Undone, g.Undone, func(a, b Ply) bool {
+		return a.Player == b.Player && a.Idx == b.Idx && a.Hash == b.Hash && slices.Equal(a.Captured, b.Captured)
+	}) {
+		t.Fatalf("loaded undone plies %v, expected %v", lg.Undone, g.Undone)
+	}
+
+	for range 3 {
+		if err := lg.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !snap(lg.Board).equal(want) {
+		t.Errorf("redone after loading to turn %d with %d pairs captured, expected turn %d with %d", lg.Board.Turn, lg.Board.Captures(P1), want.turn, want.captures[0])
+	}
+}
@@ -0,0 +1,282 @@
+package tictactoe
+
+import (
+	"fmt"
+	"slices"
+)
+
+// lineTable lists every window of K cells that a row can be made in. It only
+// depends on the shape of the board and its blocked cells, so clones share
+// it.
+type lineTable struct {
+	k      int
+	cells  [][]int
+	dirs   []Dir
+	byCell [][]int
+}
+
+// lineCounts keeps how many stones each player has in every window, so rows
+// and the windows still open to a player can be looked up without scanning
+// the board.
+type lineCounts struct {
+	table  *lineTable
+	counts []lineCount
+	// full is how many windows each player fills completely and open how many
+	// hold no stones of anyone else.
+	full [MaxPlayers]int
+	open [MaxPlayers]int
+}
+
+func (b *Board) initLines() {
+	if b.Rules.Unbounded {
+		b.counts = nil
+		return
+	}
+
+	table := &lineTable{k: b.K, byCell: make([][]int, len(b.Cells))}
+	seen := map[string]bool{}
+	for start := range b.Cells {
+		for _, d := range b.lines() {
+			if b.lineLength(d) < b.K {
+				continue
+			}
+
+			cells, ok := b.window(start, d)
+			if !ok {
+				continue
+			}
+
+			// On a wrapped line exactly K long every start gives the same cells.
+			if b.Rules.Wrap {
+				key := fmt.Sprint(slices.Sorted(slices.Values(cells)))
+				if seen[key] {
+					continue
+				}
+
+				seen[key] = true
+			}
+
+			for _, idx := range cells {
+				table.byCell[idx] = append(table.byCell[idx], len(table.cells))
+			}
+
+			table.cells = append(table.cells, cells)
+			table.dirs = append(table.dirs, d)
+		}
+	}
+
+	lc := &lineCounts{
+		table:  table,
+		counts: make([]lineCount, len(table.cells)),
+	}
+
+	for i := range MaxPlayers {
+		lc.open[i] = len(table.cells)
+	}
+
+	b.counts = lc
+	for idx, p := range b.Cells {
+		if p.IsStone() {
+			lc.add(idx, p)
+		}
+	}
+}
+
+// window returns the K cells from start along d, unless the window leaves the
+// board or crosses a blocked cell.
+func (b *Board) window(start int, d Dir) ([]int, bool) {
+	cells := make([]int, b.K)
+	for i := range b.K {
+		idx, ok := b.step(start, d, i)
+		if !ok || b.At(idx) == Blocked {
+			return nil, false
+		}
+
+		cells[i] = idx
+	}
+
+	return cells, true
+}
+
+func (lc *lineCounts) clone() *lineCounts {
+	clone := *lc
+	clone.counts = slices.Clone(lc.counts)
+	return &clone
+}
+
+func (lc *lineCounts) add(idx int, p Player) {
+	k := uint8(lc.table.k)
+	pi := uint8(p.Idx())
+	for _, w := range lc.table.byCell[idx] {
+		c := &lc.counts[w]
+		if c.stones[pi] == 0 {
+			switch c.kinds {
+			case 0:
+				// An empty window was open to everyone.
+				for qi := range uint8(MaxPlayers) {
+					if qi != pi {
+						lc.open[qi]--
+					}
+				}
+
+				c.owner = pi
+			case 1:
+				lc.open[c.owner]--
+			}
+
+			c.kinds++
+		}
+
+		c.stones[pi]++
+		if c.stones[pi] == k {
+			lc.full[pi]++
+		}
+	}
+}
+
+func (lc *lineCounts) remove(idx int, p Player) {
+	k := uint8(lc.table.k)
+	pi := uint8(p.Idx())
+	for _, w := range lc.table.byCell[idx] {
+		c := &lc.counts[w]
+		if c.stones[pi] == k {
+			lc.full[pi]--
+		}
+
+		c.stones[pi]--
+		if c.stones[pi] > 0 {
+			continue
+		}
+
+		c.kinds--
+		switch c.kinds {
+		case 0:
+			for qi := range uint8(MaxPlayers) {
+				if qi != pi {
+					lc.open[qi]++
+				}
+			}
+		case 1:
+			for qi := range uint8(MaxPlayers) {
+				if c.stones[qi] > 0 {
+					c.owner = qi
+				}
+			}
+
+			lc.open[c.owner]++
+		}
+	}
+}
+
+// lineCount is how many stones each player has in a window. Kinds is how many
+// players have stones in it, and while that is one owner is who.
+type lineCount struct {
+	stones [MaxPlayers]uint8
+	kinds  uint8
+	owner  uint8
+}
+
+// fullLineThrough returns a window through idx that p fills completely.
+func (lc *lineCounts) fullLineThrough(idx int, p Player) (int, bool) {
+	k := lc.table.k
+	for _, w := range lc.table.byCell[idx] {
+		if int(lc.counts[w].stones[p.Idx()]) == k {
+			return w, true
+		}
+	}
+
+	return -1, false
+}
+
+// row returns a winning row of p, looking at the windows through the last
+// move first.
+func (lc *lineCounts) row(b *Board, p Player) []int {
+	if lc.full[p.Idx()] == 0 {
+		return nil
+	}
+
+	rowIn := func(w int) []int {
+		if int(lc.counts[w].stones[p.Idx()]) != lc.table.k {
+			return nil
+		}
+
+		// The row may run on past the window, so it is read from its start.
+		first, d := lc.table.cells[w][0], lc.table.dirs[w]
+		back, _ := b.run(first, d, p)
+		start, _ := b.step(first, d, -back)
+		return b.rowFrom(start, p)
+	}
+
+	if b.validIdx(b.LastMove) {
+		for _, w := range lc.table.byCell[b.LastMove] {
+			if row := rowIn(w); row != nil {
+				return row
+			}
+		}
+	}
+
+	for w := range lc.table.cells {
+		if row := rowIn(w); row != nil {
+			return row
+		}
+	}
+
+	return nil
+}
+
+// KInRow reports whether p has a winning row anywhere on the board: K or more
+// stones in a row, or exactly K where the rules do not let overlines win.
+// Boards with bitboards check all rows at once, and the line counts cover the
+// wrapped and 3D boards that bitboards can not hold.
+func (b *Board) KInRow(p Player) bool {
+	if !p.IsStone() {
+		return false
+	}
+
+	switch {
+	case b.Rules.Ultimate || !b.wins(b.K+1, p):
+		return b.GetKRow(p) != nil
+	case b.bits != nil:
+		return b.bits.kInRow(p, b.K)
+	case b.counts != nil:
+		return b.counts.full[p.Idx()] > 0
+	}
+
+	return b.GetKRow(p) != nil
+}
+
+// IsDead reports whether no player can make a row any more, so the game can
+// only end in a draw. Unbounded boards always have room for a row and captures
+// can open lines up again, so neither is ever dead.
+func (b *Board) IsDead() bool {
+	if b.counts == nil || b.Rules.Capture > 0 {
+		return false
+	}
+
+	for _, p := range b.Players() {
+		if b.Rules.Ultimate {
+			if b.metaWinnable(p) {
+				return false
+			}
+		} else if b.WinnableLines(p) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WinnableLines returns how many windows of K cells p could still fill, that
+// is how many hold no stones of the other players. It returns -1 on
+// unbounded boards, where there is no end to them.
+func (b *Board) WinnableLines(p Player) int {
+	if b.counts == nil {
+		return -1
+	}
+
+	if len(b.counts.table.cells) == 0 || !p.IsStone() {
+		return 0
+	}
+
+	return b.counts.open[p.Idx()]
+}
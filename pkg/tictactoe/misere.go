@@ -0,0 +1,69 @@
+package tictactoe
+
+// WithMisere makes completing a row lose instead of win.
+func WithMisere() Option {
+	return func(b *Board) {
+		b.Rules.Misere = true
+	}
+}
+
+// Opponent returns the other player of a two player game.
+func (b *Board) Opponent(p Player) Player {
+	if p == P1 {
+		return P2
+	}
+
+	return P1
+}
+
+// loses reports whether player playing idx completes a row, which loses under
+// misère rules.
+func (b *Board) loses(idx int, player Player) bool {
+	if b.ApplyMove(idx, player) != nil {
+		return false
+	}
+
+	defer b.UndoMove(idx)
+	return b.checkFrom(idx) == player
+}
+
+// misereTacticalMoves returns a move that leaves the opponent nothing but
+// moves that complete a row, or else the moves that do not complete a row
+// for player if some of them do.
+func (b *Board) misereTacticalMoves(player Player) ([]int, bool) {
+	moves := b.LegalMoves()
+
+	safe := []int{}
+	for _, i := range moves {
+		if !b.loses(i, player) {
+			safe = append(safe, i)
+		}
+	}
+
+	for _, i := range safe {
+		if b.ApplyMove(i, player) != nil {
+			continue
+		}
+
+		forced := b.AnyLegalMoves() && b.ToMove() != player
+		if forced {
+			for _, reply := range b.LegalMoves() {
+				if !b.loses(reply, b.ToMove()) {
+					forced = false
+					break
+				}
+			}
+		}
+
+		b.UndoMove(i)
+		if forced {
+			return []int{i}, true
+		}
+	}
+
+	if len(safe) > 0 && len(safe) < len(moves) {
+		return safe, false
+	}
+
+	return nil, false
+}
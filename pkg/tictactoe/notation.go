@@ -0,0 +1,179 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMalformedMove  = errors.New("malformed move")
+	ErrMoveOutOfRange = errors.New("move out of range")
+)
+
+// FormatMove writes idx in algebraic notation: a column letter followed by the
+// row counted from the bottom, so the center of a 15x15 board is "h8". Columns
+// past z continue with aa, ab and so on. Unbounded boards have no edges to
+// count from and use "x,y" instead. Boards with layers add the layer counted
+// from one, like "b3:2".
+func (b *Board) FormatMove(idx int) string {
+	if !b.validIdx(idx) {
+		return "?"
+	}
+
+	x, y := b.coords(idx)
+	if b.Rules.Unbounded {
+		return fmt.Sprintf("%d,%d", x, y)
+	}
+
+	s := formatColumn(x) + strconv.Itoa(b.H-y)
+	if b.D > 1 {
+		s += ":" + strconv.Itoa(b.Layer(idx)+1)
+	}
+
+	return s
+}
+
+// ParseMove reads a move written by FormatMove.
+func (b *Board) ParseMove(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if b.Rules.Unbounded {
+		return b.parseUnbounded(s)
+	}
+
+	z := 0
+	if b.D > 1 {
+		cell, layer, ok := strings.Cut(s, ":")
+		n, err := strconv.Atoi(layer)
+		if !ok || err != nil || layer[0] == '+' || layer[0] == '-' {
+			return -1, fmt.Errorf("%q: %w", s, ErrMalformedMove)
+		}
+
+		if n < 1 || n > b.D {
+			return -1, fmt.Errorf("%q on a board with %d layers: %w", s, b.D, ErrMoveOutOfRange)
+		}
+
+		s, z = cell, n-1
+	}
+
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+	if split <= 0 {
+		return -1, fmt.Errorf("%q: %w", s, ErrMalformedMove)
+	}
+
+	row, err := strconv.Atoi(s[split:])
+	if err != nil || s[split] == '+' || s[split] == '-' {
+		return -1, fmt.Errorf("%q: %w", s, ErrMalformedMove)
+	}
+
+	x, ok := parseColumn(s[:split])
+	y := b.H - row
+	if !ok || !b.InBounds(x, y) {
+		return -1, fmt.Errorf("%q on a %dx%d board: %w", s, b.W, b.H, ErrMoveOutOfRange)
+	}
+
+	return b.GetIdx3(x, y, z), nil
+}
+
+func (b *Board) parseUnbounded(s string) (int, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return -1, fmt.Errorf("%q: %w", s, ErrMalformedMove)
+	}
+
+	x, errX := strconv.Atoi(strings.TrimSpace(xs))
+	y, errY := strconv.Atoi(strings.TrimSpace(ys))
+	if errX != nil || errY != nil {
+		return -1, fmt.Errorf("%q: %w", s, ErrMalformedMove)
+	}
+
+	if !b.InBounds(x, y) {
+		return -1, fmt.Errorf("%q: %w", s, ErrMoveOutOfRange)
+	}
+
+	return b.GetIdx(x, y), nil
+}
+
+// FormatMoves writes a list of moves separated by spaces, like "h8 i9 j10".
+func (b *Board) FormatMoves(moves []int) string {
+	parts := make([]string, len(moves))
+	for i, idx := range moves {
+		parts[i] = b.FormatMove(idx)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ParseMoves reads a list of moves separated by whitespace.
+func (b *Board) ParseMoves(s string) ([]int, error) {
+	fields := strings.Fields(s)
+	moves := make([]int, 0, len(fields))
+	for i, f := range fields {
+		idx, err := b.ParseMove(f)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+
+		moves = append(moves, idx)
+	}
+
+	return moves, nil
+}
+
+func (b *Board) formatCells(cells []Move) string {
+	moves := make([]int, len(cells))
+	for i, m := range cells {
+		moves[i] = b.moveIdx(m)
+	}
+
+	return b.FormatMoves(moves)
+}
+
+func (b *Board) parseCells(s string) ([]Move, error) {
+	moves, err := b.ParseMoves(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]Move, len(moves))
+	for i, idx := range moves {
+		x, y, z := idx%b.W, idx/b.W%b.H, idx/(b.W*b.H)
+		cells[i] = Move{X: x, Y: y, Z: z}
+	}
+
+	return cells, nil
+}
+
+// Moves returns the cells played so far, in order.
+func (b *Board) Moves() []int {
+	moves := make([]int, len(b.History))
+	for i, ply := range b.History {
+		moves[i] = ply.Idx
+	}
+
+	return moves
+}
+
+func formatColumn(x int) string {
+	var col []byte
+	for x++; x > 0; x = (x - 1) / 26 {
+		col = append([]byte{byte('a' + (x-1)%26)}, col...)
+	}
+
+	return string(col)
+}
+
+func parseColumn(s string) (int, bool) {
+	x := 0
+	for _, r := range s {
+		x = x*26 + int(r-'a') + 1
+		if x > unboundedSpan {
+			return 0, false
+		}
+	}
+
+	return x - 1, true
+}
@@ -0,0 +1,229 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errWrongPhase = errors.New("not allowed in this phase")
+
+type Opening int8
+
+const (
+	NoOpening Opening = iota
+	// Pie lets the second player take over the first stone.
+	Pie
+	// Swap has the first player place X, O, X after which the second player
+	// picks a color.
+	Swap
+	// Swap2 is Swap where the second player may also place one more O and X
+	// and hand the choice of color back to the first player.
+	Swap2
+)
+
+var openingNames = []string{"none", "pie", "swap", "swap2"}
+
+func (o Opening) String() string {
+	if o < 0 || int(o) >= len(openingNames) {
+		return fmt.Sprintf("Opening(%d)", o)
+	}
+
+	return openingNames[o]
+}
+
+func ParseOpening(s string) (Opening, error) {
+	for i, name := range openingNames {
+		if name == s {
+			return Opening(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown opening %q", s)
+}
+
+func WithOpening(o Opening) Option {
+	return func(b *Board) {
+		b.Rules.Opening = o
+	}
+}
+
+type Phase int8
+
+const (
+	PhasePlay Phase = iota
+	// PhaseOpening is while the opening stones are being placed.
+	PhaseOpening
+	// PhaseChoose is while a seat picks which color to play.
+	PhaseChoose
+)
+
+var phaseNames = []string{"play", "opening", "choose"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return fmt.Sprintf("Phase(%d)", p)
+	}
+
+	return phaseNames[p]
+}
+
+// Seat identifies a participant independently of the color they play. Seats
+// follow the turn order, so seat 0 is the one who places the first stone.
+type Seat int8
+
+type Choice int8
+
+const (
+	ChooseP1 Choice = iota
+	ChooseP2
+	ChoosePlaceTwo
+)
+
+func (c Choice) String() string {
+	switch c {
+	case ChooseP1:
+		return "play " + P1.Mark()
+	case ChooseP2:
+		return "play " + P2.Mark()
+	case ChoosePlaceTwo:
+		return "place two more stones"
+	}
+
+	return fmt.Sprintf("Choice(%d)", c)
+}
+
+func (g *Game) openingStones() int {
+	switch g.Board.Rules.Opening {
+	case Pie:
+		return 1
+	case Swap:
+		return 3
+	case Swap2:
+		if g.Extended {
+			return 5
+		}
+
+		return 3
+	}
+
+	return 0
+}
+
+// PlayMove plays idx for the player to move and advances the opening. Any
+// undone plies are dropped.
+func (g *Game) PlayMove(idx int) error {
+	if err := g.play(idx); err != nil {
+		return err
+	}
+
+	g.Undone = nil
+	return nil
+}
+
+func (g *Game) play(idx int) error {
+	if g.Status().Over() {
+		return fmt.Errorf("move %d: %w", idx, errGameOver)
+	}
+
+	if g.Phase == PhaseChoose {
+		return fmt.Errorf("move %d: %w", idx, errWrongPhase)
+	}
+
+	if err := g.Board.ApplyMove(idx, g.Board.ToMove()); err != nil {
+		return err
+	}
+
+	if g.Phase == PhaseOpening && g.Board.Turn >= g.openingStones() {
+		g.Phase = PhaseChoose
+	}
+
+	return nil
+}
+
+// Chooser returns the seat that picks a color in PhaseChoose.
+func (g *Game) Chooser() Seat {
+	if g.Extended {
+		return 0
+	}
+
+	return 1
+}
+
+func (g *Game) Choices() []Choice {
+	if g.Phase != PhaseChoose {
+		return nil
+	}
+
+	if g.Board.Rules.Opening == Swap2 && !g.Extended {
+		return []Choice{ChooseP1, ChooseP2, ChoosePlaceTwo}
+	}
+
+	return []Choice{ChooseP1, ChooseP2}
+}
+
+func (g *Game) Choose(c Choice) error {
+	if g.Status().Over() {
+		return fmt.Errorf("choice %s: %w", c, errGameOver)
+	}
+
+	if g.Phase != PhaseChoose {
+		return fmt.Errorf("choice %s: %w", c, errWrongPhase)
+	}
+
+	chooser := g.Chooser()
+	switch c {
+	case ChooseP1:
+		g.Swapped = chooser == 1
+	case ChooseP2:
+		g.Swapped = chooser == 0
+	case ChoosePlaceTwo:
+		if g.Board.Rules.Opening != Swap2 || g.Extended {
+			return fmt.Errorf("choice %s: %w", c, errWrongPhase)
+		}
+
+		g.Extended = true
+		g.Phase = PhaseOpening
+		return nil
+	default:
+		return fmt.Errorf("unknown choice %d", c)
+	}
+
+	g.Phase = PhasePlay
+	return nil
+}
+
+// Color returns the player the seat is playing as.
+func (g *Game) Color(s Seat) Player {
+	if g.Swapped && s < 2 {
+		s = 1 - s
+	}
+
+	return g.Board.Players()[s]
+}
+
+func (g *Game) Seat(p Player) Seat {
+	for s := range Seat(len(g.Board.Players())) {
+		if g.Color(s) == p {
+			return s
+		}
+	}
+
+	return -1
+}
+
+// SeatToMove returns the seat that has to act next, whether that is placing
+// a stone or picking a color.
+func (g *Game) SeatToMove() Seat {
+	switch g.Phase {
+	case PhaseOpening:
+		if g.Extended {
+			return 1
+		}
+
+		return 0
+	case PhaseChoose:
+		return g.Chooser()
+	}
+
+	return g.Seat(g.Board.ToMove())
+}
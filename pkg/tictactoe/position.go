@@ -0,0 +1,297 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrMalformedPosition = errors.New("malformed position")
+
+// Position writes the board as a single line of fields separated by spaces:
+// the size, K, the rules, the cells, the player to move, how many plies were
+// played and the last of them, or "-" before the first one. Games with an
+// opening follow with how far it got, the phase with "+swapped" once the
+// colors were swapped and "+extended" once swap2 stones were added, like
+// "choose+extended". Games with captures end with the pairs each player has
+// captured, like "2,0". After the first move on a 7x7 board it reads
+//
+//	7x7 4 freestyle 7/7/7/3X3/7/7/7 O 1 d4
+//
+// Cells are written row by row from the top, rows separated by "/" and layers
+// by "|". Stones are written by their mark, blocked cells as "#" and runs of
+// empty cells as their length. The history is not part of the position.
+func (b *Board) Position() (string, error) {
+	if b.Rules.Unbounded {
+		return "", errors.New("unbounded boards have no position")
+	}
+
+	size := fmt.Sprintf("%dx%d", b.W, b.H)
+	if b.D > 1 {
+		size += fmt.Sprintf("x%d", b.D)
+	}
+
+	layers := make([]string, b.depth())
+	for z := range layers {
+		rows := make([]string, b.H)
+		for y := range rows {
+			var row strings.Builder
+			empty := 0
+			for x := range b.W {
+				p := b.At(b.GetIdx3(x, y, z))
+				if p == Empty {
+					empty++
+					continue
+				}
+
+				if empty > 0 {
+					row.WriteString(strconv.Itoa(empty))
+					empty = 0
+				}
+
+				row.WriteString(p.Mark())
+			}
+
+			if empty > 0 {
+				row.WriteString(strconv.Itoa(empty))
+			}
+
+			rows[y] = row.String()
+		}
+
+		layers[z] = strings.Join(rows, "/")
+	}
+
+	last := "-"
+	if b.Turn > 0 {
+		last = b.FormatMove(b.LastMove)
+	}
+
+	fields := []string{size, strconv.Itoa(b.K), b.Rules.String(), strings.Join(layers, "|"), b.ToMove().Mark(), strconv.Itoa(b.Turn), last}
+	if b.Rules.Opening != NoOpening {
+		fields = append(fields, b.game.positionOpening())
+	}
+
+	if b.Rules.Capture > 0 {
+		captures := make([]string, len(b.Players()))
+		for i, p := range b.Players() {
+			captures[i] = strconv.Itoa(b.Captures(p))
+		}
+
+		fields = append(fields, strings.Join(captures, ","))
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// NewFromPosition starts a game from a position written by Board.Position. The
+// position becomes the setup of the game, so its stones can not be taken back.
+func NewFromPosition(s string) (*Game, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 7 {
+		return nil, fmt.Errorf("%q has %d fields, expected at least 7: %w", s, len(fields), ErrMalformedPosition)
+	}
+
+	dims := strings.Split(fields[0], "x")
+	size := make([]int, 3)
+	for i, dim := range dims {
+		n, err := strconv.Atoi(dim)
+		if err != nil || len(dims) < 2 || len(dims) > 3 {
+			return nil, fmt.Errorf("size %q: %w", fields[0], ErrMalformedPosition)
+		}
+
+		size[i] = n
+	}
+
+	w, h, d := size[0], size[1], size[2]
+
+	k, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("win condition %q: %w", fields[1], ErrMalformedPosition)
+	}
+
+	rules, err := ParseRules(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPosition, err)
+	}
+
+	if rules.Unbounded {
+		return nil, fmt.Errorf("unbounded boards have no position: %w", ErrMalformedPosition)
+	}
+
+	wantFields := 7
+	if rules.Opening != NoOpening {
+		wantFields++
+	}
+
+	if rules.Capture > 0 {
+		wantFields++
+	}
+
+	if len(fields) != wantFields {
+		return nil, fmt.Errorf("%q has %d fields, expected %d: %w", s, len(fields), wantFields, ErrMalformedPosition)
+	}
+
+	setup, err := parsePositionCells(fields[3], w, h, max(1, d))
+	if err != nil {
+		return nil, err
+	}
+
+	if setup.Turn, err = strconv.Atoi(fields[5]); err != nil || setup.Turn < 0 {
+		return nil, fmt.Errorf("turn %q: %w", fields[5], ErrMalformedPosition)
+	}
+
+	if fields[6] != "-" {
+		// The move is read on a board of the right size before the game is
+		// made.
+		notation := &Board{W: w, H: h, D: d}
+		idx, err := notation.ParseMove(fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("%w: last move: %w", ErrMalformedPosition, err)
+		}
+
+		setup.LastMove = &Move{X: idx % w, Y: idx / w % h, Z: idx / (w * h)}
+	}
+
+	if (setup.LastMove == nil) != (setup.Turn == 0) {
+		return nil, fmt.Errorf("last move %q after %d plies: %w", fields[6], setup.Turn, ErrMalformedPosition)
+	}
+
+	if rules.Capture > 0 {
+		captures := fields[len(fields)-1]
+		for _, c := range strings.Split(captures, ",") {
+			n, err := strconv.Atoi(c)
+			if err != nil {
+				return nil, fmt.Errorf("captures %q: %w", captures, ErrMalformedPosition)
+			}
+
+			setup.Captures = append(setup.Captures, n)
+		}
+	}
+
+	g, err := New(w, h, k, WithRules(rules), WithDepth(d), WithSetup(setup))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPosition, err)
+	}
+
+	if mark := g.Board.ToMove().Mark(); fields[4] != mark {
+		return nil, fmt.Errorf("%s to move after %d plies, not %s: %w", mark, setup.Turn, fields[4], ErrMalformedPosition)
+	}
+
+	if rules.Opening != NoOpening {
+		if err := g.parsePositionOpening(fields[7]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPosition, err)
+	}
+
+	return g, nil
+}
+
+// positionOpening writes how far the opening of the game got.
+func (g *Game) positionOpening() string {
+	s := g.Phase.String()
+	if g.Swapped {
+		s += "+swapped"
+	}
+
+	if g.Extended {
+		s += "+extended"
+	}
+
+	return s
+}
+
+// parsePositionOpening picks the opening up from where a position left it.
+func (g *Game) parsePositionOpening(s string) error {
+	parts := strings.Split(s, "+")
+	phase := slices.Index(phaseNames, parts[0])
+	if phase < 0 {
+		return fmt.Errorf("opening %q: %w", s, ErrMalformedPosition)
+	}
+
+	g.Phase, g.Swapped, g.Extended = Phase(phase), false, false
+	for _, part := range parts[1:] {
+		switch part {
+		case "swapped":
+			g.Swapped = true
+		case "extended":
+			g.Extended = true
+		default:
+			return fmt.Errorf("opening %q: %w", s, ErrMalformedPosition)
+		}
+	}
+
+	// Colors are only swapped by the last choice, after which play goes on.
+	turn, stones := g.Board.Turn, g.openingStones()
+	switch {
+	case g.Extended && g.Board.Rules.Opening != Swap2,
+		g.Swapped && g.Phase != PhasePlay,
+		g.Phase == PhaseOpening && turn >= stones,
+		g.Phase == PhaseChoose && turn != stones,
+		g.Phase == PhasePlay && turn < stones:
+		return fmt.Errorf("opening %q after %d plies: %w", s, turn, ErrMalformedPosition)
+	}
+
+	return nil
+}
+
+// parsePositionCells reads the cells of a position into a setup with the
+// stones and blocked cells on them.
+func parsePositionCells(s string, w, h, d int) (Setup, error) {
+	marks := map[rune]Player{}
+	for _, p := range append(slices.Clone(turnOrder), Blocked) {
+		marks[rune(p.Mark()[0])] = p
+	}
+
+	layers := strings.Split(s, "|")
+	if len(layers) != d {
+		return Setup{}, fmt.Errorf("%d layers, expected %d: %w", len(layers), d, ErrMalformedPosition)
+	}
+
+	setup := Setup{Stones: map[Player][]Move{}}
+	for z, layer := range layers {
+		rows := strings.Split(layer, "/")
+		if len(rows) != h {
+			return Setup{}, fmt.Errorf("%d rows, expected %d: %w", len(rows), h, ErrMalformedPosition)
+		}
+
+		for y, row := range rows {
+			x, empty := 0, 0
+			for _, r := range row {
+				if r >= '0' && r <= '9' {
+					empty = empty*10 + int(r-'0')
+					continue
+				}
+
+				x += empty
+				empty = 0
+
+				p, ok := marks[r]
+				if !ok || x >= w {
+					return Setup{}, fmt.Errorf("row %q: %w", row, ErrMalformedPosition)
+				}
+
+				m := Move{X: x, Y: y, Z: z}
+				if p == Blocked {
+					setup.Blocked = append(setup.Blocked, m)
+				} else {
+					setup.Stones[p] = append(setup.Stones[p], m)
+				}
+
+				x++
+			}
+
+			if x+empty != w {
+				return Setup{}, fmt.Errorf("row %q has %d cells, expected %d: %w", row, x+empty, w, ErrMalformedPosition)
+			}
+		}
+	}
+
+	return setup, nil
+}
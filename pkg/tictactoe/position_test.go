@@ -0,0 +1,200 @@
+package tictactoe
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPositionOpening(t *testing.T) {
+	tests := []struct {
+		name    string
+		opening Opening
+		moves   []int
+		choices []Choice
+		want    string
+		seat    Seat
+	}{
+		{"swap2 placing", Swap2, []int{0, 1}, nil, "opening", 0},
+		{"swap2 choosing", Swap2, []int{0, 1, 2}, nil, "choose", 1},
+		{"swap2 placing two", Swap2, []int{0, 1, 2, 3}, []Choice{ChoosePlaceTwo}, "opening+extended", 1},
+		{"swap2 choosing back", Swap2, []int{0, 1, 2, 3, 4}, []Choice{ChoosePlaceTwo}, "choose+extended", 0},
+		{"swap2 swapped", Swap2, []int{0, 1, 2, 3}, []Choice{ChooseP1}, "play+swapped", 1},
+		{"pie kept", Pie, []int{0, 1}, []Choice{ChooseP2}, "play", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(7, 7, 4, WithOpening(tt.opening))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Choices are made as soon as they are asked for.
+			choices := tt.choices
+			for _, idx := range tt.moves {
+				if g.Phase == PhaseChoose && len(choices) > 0 {
+					if err := g.Choose(choices[0]); err != nil {
+						t.Fatal(err)
+					}
+
+					choices = choices[1:]
+				}
+
+				if err := g.PlayMove(idx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pos, err := g.Board.Position()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := NewFromPosition(pos)
+			if err != nil {
+				t.Fatalf("%q: %v", pos, err)
+			}
+
+			if got := loaded.positionOpening(); got != tt.want {
+				t.Errorf("%q loaded with opening %q, expected %q", pos, got, tt.want)
+			}
+
+			if loaded.Phase != g.Phase || loaded.Swapped != g.Swapped || loaded.Extended != g.Extended {
+				t.Errorf("%q loaded in %s, swapped %t, extended %t, expected %s, %t, %t", pos, loaded.Phase, loaded.Swapped, loaded.Extended, g.Phase, g.Swapped, g.Extended)
+			}
+
+			if seat := loaded.SeatToMove(); seat != tt.seat || seat != g.SeatToMove() {
+				t.Errorf("%q loaded with seat %d to act, expected %d", pos, seat, tt.seat)
+			}
+		})
+	}
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		opts    []Option
+		moves   []Move
+	}{
+		{"empty board", 3, 3, 3, nil, nil},
+		{"after the first move", 7, 7, 4, nil, []Move{{X: 3, Y: 3}}},
+		{"wide board", 12, 3, 3, nil, []Move{{X: 11, Y: 0}, {X: 0, Y: 2}, {X: 10, Y: 1}}},
+		{"three players", 5, 5, 4, []Option{WithPlayers(3)}, []Move{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}}},
+		{"blocked cells", 5, 5, 4, []Option{WithBlocked(Move{X: 2, Y: 2}, Move{X: 4, Y: 0})}, []Move{{X: 1, Y: 1}, {X: 3, Y: 3}}},
+		{"handicap stones", 7, 7, 5, []Option{WithStones(P1, Move{X: 3, Y: 3})}, []Move{{X: 4, Y: 4}}},
+		{"gravity", 7, 6, 4, []Option{WithGravity()}, []Move{{X: 3, Y: 5}, {X: 3, Y: 4}}},
+		{"capture", 7, 7, 5, []Option{WithCaptures(5)}, []Move{{X: 3, Y: 3}, {X: 4, Y: 3}, {X: 0, Y: 0}, {X: 5, Y: 3}, {X: 6, Y: 3}}},
+		{"cube", 3, 3, 3, []Option{WithDepth(3)}, []Move{{X: 1, Y: 1, Z: 1}, {X: 0, Y: 0, Z: 2}, {X: 2, Y: 0, Z: 0}}},
+		{"cube with blocked cells", 4, 4, 3, []Option{WithDepth(4), WithBlocked(Move{X: 1, Y: 2, Z: 3})}, []Move{{X: 3, Y: 3, Z: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, m := range tt.moves {
+				if err := g.PlayMove(g.Board.moveIdx(m)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pos, err := g.Board.Position()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := NewFromPosition(pos)
+			if err != nil {
+				t.Fatalf("%q: %v", pos, err)
+			}
+
+			b, lb := g.Board, loaded.Board
+			if again, err := lb.Position(); err != nil || again != pos {
+				t.Errorf("%q written back as %q, %v", pos, again, err)
+			}
+
+			if !slices.Equal(lb.Cells, b.Cells) {
+				t.Errorf("%q loaded cells %v, expected %v", pos, lb.Cells, b.Cells)
+			}
+
+			if lb.Turn != b.Turn || lb.LastMove != b.LastMove || lb.ToMove() != b.ToMove() {
+				t.Errorf("%q loaded on turn %d after %d with %s to move, expected %d after %d with %s",
+					pos, lb.Turn, lb.LastMove, lb.ToMove().Mark(), b.Turn, b.LastMove, b.ToMove().Mark())
+			}
+
+			for _, p := range b.Players() {
+				if lb.Captures(p) != b.Captures(p) {
+					t.Errorf("%q loaded with %d pairs captured by %s, expected %d", pos, lb.Captures(p), p.Mark(), b.Captures(p))
+				}
+			}
+
+			// The loaded game plays on from the position like the original.
+			idx := b.LegalMoves()[0]
+			if err := g.PlayMove(idx); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := loaded.PlayMove(idx); err != nil {
+				t.Fatalf("%q: playing on: %v", pos, err)
+			}
+
+			if !slices.Equal(lb.Cells, b.Cells) {
+				t.Errorf("%q played on to cells %v, expected %v", pos, lb.Cells, b.Cells)
+			}
+		})
+	}
+}
+
+func TestPositionCaptured(t *testing.T) {
+	g, err := NewFromPosition("7x7 5 freestyle+capture5 7/7/7/3X2X/7/7/7 O 5 g4 1,0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := g.Board.Captures(P1); got != 1 {
+		t.Errorf("X captured %d pairs, expected 1", got)
+	}
+}
+
+func TestNewFromPositionMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  string
+	}{
+		{"too few fields", "3x3 3 freestyle 3/3/3 X 0"},
+		{"too many fields", "3x3 3 freestyle 3/3/3 X 0 - 0,0"},
+		{"size without height", "3 3 freestyle 3/3/3 X 0 -"},
+		{"size not a number", "3xc 3 freestyle 3/3/3 X 0 -"},
+		{"size with four dimensions", "3x3x3x3 3 freestyle 3/3/3 X 0 -"},
+		{"win condition not a number", "3x3 three freestyle 3/3/3 X 0 -"},
+		{"row longer than the board", "3x3 4 freestyle 3/3/3 X 0 -"},
+		{"unknown rules", "3x3 3 checkers 3/3/3 X 0 -"},
+		{"missing row", "3x3 3 freestyle 3/3 X 0 -"},
+		{"short row", "3x3 3 freestyle 3/2/3 X 0 -"},
+		{"long row", "3x3 3 freestyle 3/XXXX/3 X 0 -"},
+		{"unknown mark", "3x3 3 freestyle 3/1Q1/3 X 0 -"},
+		{"missing layer", "3x3x3 3 freestyle 3/3/3|3/3/3 X 0 -"},
+		{"wrong player to move", "3x3 3 freestyle 3/1X1/3 X 1 b2"},
+		{"negative turn", "3x3 3 freestyle 3/3/3 X -1 -"},
+		{"no last move", "3x3 3 freestyle 3/1X1/3 O 1 -"},
+		{"last move before the first", "3x3 3 freestyle 3/3/3 X 0 b2"},
+		{"last move off the board", "3x3 3 freestyle 3/1X1/3 O 1 z9"},
+		{"two rows", "3x3 3 freestyle XXX/OOO/3 X 6 c2"},
+		{"missing captures", "7x7 5 freestyle+capture5 7/7/7/3X3/7/7/7 O 1 d4"},
+		{"captures not numbers", "7x7 5 freestyle+capture5 7/7/7/3X3/7/7/7 O 1 d4 a,b"},
+		{"unbounded", "3x3 3 freestyle+unbounded 3/3/3 X 0 -"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFromPosition(tt.pos); !errors.Is(err, ErrMalformedPosition) {
+				t.Errorf("NewFromPosition(%q): %v, expected %v", tt.pos, err, ErrMalformedPosition)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// record is what the game record formats have in common.
+type record struct {
+	W, H, D, K int
+	Rules      Rules
+	Setup      *Setup
+
+	// Names holds the names of whoever played P1 and P2.
+	Names [2]string
+
+	Winner  Player
+	Draw    bool
+	Outcome Outcome
+
+	// Choices are the choices made during the opening, in order.
+	Choices []Choice
+
+	// Moves are the stones in the order they were played. A move without a
+	// player is played by whoever is to move.
+	Moves []Ply
+}
+
+// outcomeCodes are how records write a game that was not decided on the
+// board, after the winner like "B+R". The long forms are read as well.
+var outcomeCodes = map[Outcome]string{
+	Resigned:  "R",
+	TimedOut:  "T",
+	Forfeited: "F",
+}
+
+var outcomeLongCodes = map[Outcome]string{
+	Resigned:  "Resign",
+	TimedOut:  "Time",
+	Forfeited: "Forfeit",
+}
+
+// parseOutcome reads how a game was won. Anything but the codes, like a score,
+// is a game won on the board.
+func parseOutcome(s string) Outcome {
+	for o, code := range outcomeCodes {
+		if s == code || s == outcomeLongCodes[o] {
+			return o
+		}
+	}
+
+	return Won
+}
+
+var choiceCodes = map[Choice]string{
+	ChooseP1:       "x",
+	ChooseP2:       "o",
+	ChoosePlaceTwo: "p",
+}
+
+func parseChoice(s string) (Choice, error) {
+	for c, code := range choiceCodes {
+		if code == s {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown opening choice %q", s)
+}
+
+func (g *Game) record() (*record, error) {
+	b := g.Board
+	if b.Rules.Unbounded {
+		return nil, errors.New("unbounded boards can not be recorded")
+	}
+
+	if len(b.Players()) != 2 {
+		return nil, fmt.Errorf("records only hold two player games, not %d", len(b.Players()))
+	}
+
+	if b.setupTurn() > 0 {
+		return nil, errors.New("games set up part way through can not be recorded")
+	}
+
+	stones := b.setupStones()
+	for _, ply := range b.History {
+		stones += 1 - len(ply.Captured)
+	}
+
+	for _, p := range b.occupied() {
+		if p.IsStone() {
+			stones--
+		}
+	}
+
+	if stones != 0 {
+		return nil, errors.New("board has stones that are not in its move history")
+	}
+
+	r := &record{
+		W:     b.W,
+		H:     b.H,
+		D:     b.D,
+		K:     b.K,
+		Rules: b.Rules,
+		Setup: b.Setup,
+		Moves: b.History,
+	}
+
+	status := g.Status()
+	r.Winner, r.Draw, r.Outcome = status.Winner, status.Outcome == Drawn, status.Outcome
+
+	for i, p := range []Player{P1, P2} {
+		if s := g.Seat(p); int(s) < len(g.Names) {
+			r.Names[i] = g.Names[s]
+		}
+	}
+
+	if g.Extended {
+		r.Choices = append(r.Choices, ChoosePlaceTwo)
+	}
+
+	if b.Rules.Opening != NoOpening && g.Phase == PhasePlay {
+		c := ChooseP2
+		if g.Swapped == (g.Chooser() == 1) {
+			c = ChooseP1
+		}
+
+		r.Choices = append(r.Choices, c)
+	}
+
+	return r, nil
+}
+
+// replay plays the record out on a new game.
+func (r *record) replay() (*Game, error) {
+	opts := []Option{WithRules(r.Rules), WithDepth(r.D)}
+	if r.Setup != nil {
+		opts = append(opts, WithBlocked(r.Setup.Blocked...))
+		for p, cells := range r.Setup.Stones {
+			opts = append(opts, WithStones(p, cells...))
+		}
+	}
+
+	g, err := New(r.W, r.H, r.K, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	choices := r.Choices
+	choose := func() error {
+		if len(choices) == 0 {
+			return errors.New("record is missing an opening choice")
+		}
+
+		if err := g.Choose(choices[0]); err != nil {
+			return err
+		}
+
+		choices = choices[1:]
+		return nil
+	}
+
+	for i, ply := range r.Moves {
+		for g.Phase == PhaseChoose {
+			if err := choose(); err != nil {
+				return nil, err
+			}
+		}
+
+		if ply.Player != Empty && ply.Player != g.Board.ToMove() {
+			return nil, fmt.Errorf("move %d: %s played out of turn", i+1, ply.Player.Mark())
+		}
+
+		if err := g.PlayMove(ply.Idx); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+
+	if g.Phase == PhaseChoose && len(choices) > 0 {
+		if err := choose(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Games won on the board end by themselves when replayed.
+	switch r.Outcome {
+	case Resigned, TimedOut, Forfeited:
+		if r.Winner != P1 && r.Winner != P2 {
+			return nil, fmt.Errorf("record is %s without a winner", r.Outcome)
+		}
+
+		if err := g.end(r.Outcome, g.Board.Opponent(r.Winner)); err != nil {
+			return nil, fmt.Errorf("record is %s: %w", r.Outcome, err)
+		}
+	}
+
+	for i, p := range []Player{P1, P2} {
+		if r.Names[i] == "" {
+			continue
+		}
+
+		if g.Names == nil {
+			g.Names = make([]string, 2)
+		}
+
+		g.Names[g.Seat(p)] = r.Names[i]
+	}
+
+	return g, nil
+}
@@ -0,0 +1,65 @@
+package tictactoe
+
+import "testing"
+
+func TestRecordOutcome(t *testing.T) {
+	formats := []struct {
+		name  string
+		write func(*Game) ([]byte, error)
+		load  func([]byte) (*Game, error)
+	}{
+		{"sgf", (*Game).SGF, LoadSGF},
+		{"rif", (*Game).RIF, LoadRIF},
+	}
+
+	tests := []struct {
+		name  string
+		moves []int
+		end   func(*Game) error
+		want  Status
+	}{
+		{"won", []int{0, 3, 1, 4, 2}, nil, Status{Outcome: Won, Winner: P1, Line: []int{0, 1, 2}, Ply: 5}},
+		{"resigned", []int{4}, func(g *Game) error { return g.Resign(P2) }, Status{Outcome: Resigned, Winner: P1, Loser: P2, Ply: 1}},
+		{"timed out", []int{4, 0}, func(g *Game) error { return g.TimeOut(P1) }, Status{Outcome: TimedOut, Winner: P2, Loser: P1, Ply: 2}},
+		{"forfeited", nil, func(g *Game) error { return g.Forfeit(P1) }, Status{Outcome: Forfeited, Winner: P2, Loser: P1}},
+		{"ongoing", []int{4, 0}, nil, Status{Ply: 2}},
+	}
+
+	for _, f := range formats {
+		for _, tt := range tests {
+			t.Run(f.name+"/"+tt.name, func(t *testing.T) {
+				g, err := New(3, 3, 3)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, idx := range tt.moves {
+					if err := g.PlayMove(idx); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				if tt.end != nil {
+					if err := tt.end(g); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				data, err := f.write(g)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				loaded, err := f.load(data)
+				if err != nil {
+					t.Fatalf("%s: %v", data, err)
+				}
+
+				got := loaded.Status()
+				if got.Outcome != tt.want.Outcome || got.Winner != tt.want.Winner || got.Loser != tt.want.Loser || got.Ply != tt.want.Ply || len(got.Line) != len(tt.want.Line) {
+					t.Errorf("loaded %+v from\n%s\nexpected %+v", got, data, tt.want)
+				}
+			})
+		}
+	}
+}
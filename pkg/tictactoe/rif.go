@@ -0,0 +1,176 @@
+package tictactoe
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// rifDatabase follows the layout of the Renju International Federation game
+// database, with the board size, K and rules added as attributes.
+type rifDatabase struct {
+	XMLName xml.Name  `xml:"database"`
+	Games   []rifGame `xml:"games>game"`
+}
+
+type rifGame struct {
+	Width   int    `xml:"width,attr"`
+	Height  int    `xml:"height,attr"`
+	Depth   int    `xml:"depth,attr,omitempty"`
+	K       int    `xml:"k,attr"`
+	Rule    string `xml:"rule,attr"`
+	Black   string `xml:"black,attr,omitempty"`
+	White   string `xml:"white,attr,omitempty"`
+	BResult string `xml:"bresult,attr,omitempty"`
+	Opening string `xml:"opening,attr,omitempty"`
+
+	// Ending says how a game not decided on the board was won, like "R" for
+	// a resignation.
+	Ending string `xml:"ending,attr,omitempty"`
+
+	// Blocked, SetupBlack and SetupWhite hold the setup the game started from.
+	Blocked    string `xml:"blocked,attr,omitempty"`
+	SetupBlack string `xml:"setupblack,attr,omitempty"`
+	SetupWhite string `xml:"setupwhite,attr,omitempty"`
+
+	Move string `xml:"move"`
+}
+
+// RIF writes the game as a RIF style XML record, with the moves in algebraic
+// notation and the result from black's side: 1, 0.5 or 0.
+func (g *Game) RIF() ([]byte, error) {
+	r, err := g.record()
+	if err != nil {
+		return nil, err
+	}
+
+	game := rifGame{
+		Width:  r.W,
+		Height: r.H,
+		Depth:  r.D,
+		K:      r.K,
+		Rule:   r.Rules.String(),
+		Black:  r.Names[0],
+		White:  r.Names[1],
+	}
+
+	switch {
+	case r.Winner == P1:
+		game.BResult = "1"
+	case r.Winner == P2:
+		game.BResult = "0"
+	case r.Draw:
+		game.BResult = "0.5"
+	}
+
+	game.Ending = outcomeCodes[r.Outcome]
+
+	codes := make([]string, len(r.Choices))
+	for i, c := range r.Choices {
+		codes[i] = choiceCodes[c]
+	}
+
+	game.Opening = strings.Join(codes, " ")
+	game.Move = g.Board.FormatMoves(g.Board.Moves())
+
+	if r.Setup != nil {
+		game.Blocked = g.Board.formatCells(r.Setup.Blocked)
+		game.SetupBlack = g.Board.formatCells(r.Setup.Stones[P1])
+		game.SetupWhite = g.Board.formatCells(r.Setup.Stones[P2])
+	}
+
+	data, err := xml.MarshalIndent(rifDatabase{Games: []rifGame{game}}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// LoadRIF reads the first game of a record written by Game.RIF.
+func LoadRIF(data []byte) (*Game, error) {
+	db := rifDatabase{}
+	if err := xml.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("rif: %w", err)
+	}
+
+	if len(db.Games) == 0 {
+		return nil, errors.New("rif: no games")
+	}
+
+	game := db.Games[0]
+	r := &record{
+		W:     game.Width,
+		H:     game.Height,
+		D:     game.Depth,
+		K:     game.K,
+		Names: [2]string{game.Black, game.White},
+	}
+
+	if r.W == 0 && r.H == 0 {
+		r.W, r.H = 15, 15
+	}
+
+	if r.K == 0 {
+		r.K = 5
+	}
+
+	if game.Rule != "" {
+		rules, err := ParseRules(strings.ToLower(game.Rule))
+		if err != nil {
+			return nil, fmt.Errorf("rif: %w", err)
+		}
+
+		r.Rules = rules
+	}
+
+	switch game.BResult {
+	case "1":
+		r.Winner = P1
+	case "0":
+		r.Winner = P2
+	}
+
+	if game.Ending != "" {
+		r.Outcome = parseOutcome(game.Ending)
+	}
+
+	for _, code := range strings.Fields(game.Opening) {
+		c, err := parseChoice(code)
+		if err != nil {
+			return nil, fmt.Errorf("rif: %w", err)
+		}
+
+		r.Choices = append(r.Choices, c)
+	}
+
+	// The notation only depends on the board size, so an empty board of the
+	// same size reads the moves.
+	notation := &Board{W: r.W, H: r.H, D: r.D}
+	moves, err := notation.ParseMoves(game.Move)
+	if err != nil {
+		return nil, fmt.Errorf("rif: %w", err)
+	}
+
+	if game.Blocked != "" || game.SetupBlack != "" || game.SetupWhite != "" {
+		r.Setup = &Setup{Stones: map[Player][]Move{}}
+		if r.Setup.Blocked, err = notation.parseCells(game.Blocked); err != nil {
+			return nil, fmt.Errorf("rif: blocked: %w", err)
+		}
+
+		if r.Setup.Stones[P1], err = notation.parseCells(game.SetupBlack); err != nil {
+			return nil, fmt.Errorf("rif: setup: %w", err)
+		}
+
+		if r.Setup.Stones[P2], err = notation.parseCells(game.SetupWhite); err != nil {
+			return nil, fmt.Errorf("rif: setup: %w", err)
+		}
+	}
+
+	for _, idx := range moves {
+		r.Moves = append(r.Moves, Ply{Idx: idx})
+	}
+
+	return r.replay()
+}
@@ -0,0 +1,466 @@
+package tictactoe
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RuleSet int8
+
+const (
+	// Freestyle lets any run of K or more stones win.
+	Freestyle RuleSet = iota
+	// Exact only lets runs of exactly K stones win.
+	Exact
+	// Renju is Exact for P1, who is also forbidden from making double threes,
+	// double fours and overlines. P2 wins with any run of K or more.
+	Renju
+)
+
+var ruleSetNames = []string{"freestyle", "exact", "renju"}
+
+func (r RuleSet) String() string {
+	if r < 0 || int(r) >= len(ruleSetNames) {
+		return fmt.Sprintf("RuleSet(%d)", r)
+	}
+
+	return ruleSetNames[r]
+}
+
+func ParseRuleSet(s string) (RuleSet, error) {
+	for i, name := range ruleSetNames {
+		if name == s {
+			return RuleSet(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown rule set %q", s)
+}
+
+type Rules struct {
+	Set     RuleSet `json:",omitempty"`
+	Gravity bool    `json:",omitempty"`
+	Opening Opening `json:",omitempty"`
+
+	// Stones is how many stones are placed each turn and FirstStones how many
+	// on the very first turn. Zero means one.
+	Stones      int `json:",omitempty"`
+	FirstStones int `json:",omitempty"`
+
+	// Players is how many players take turns. Zero means two.
+	Players int `json:",omitempty"`
+
+	// Wrap joins opposite edges so lines continue across them, making the
+	// board a torus.
+	Wrap bool `json:",omitempty"`
+
+	Unbounded bool `json:",omitempty"`
+
+	// Misere makes the player who completes a row lose.
+	Misere bool `json:",omitempty"`
+
+	// Capture is how many captured pairs win the game. Zero turns captures
+	// off.
+	Capture int `json:",omitempty"`
+
+	Ultimate bool `json:",omitempty"`
+}
+
+// String writes the rules as the rule set followed by any variants, joined by
+// '+', like "renju+swap2" or "freestyle+gravity+stones2/1".
+func (r Rules) String() string {
+	parts := []string{r.Set.String()}
+	if r.Gravity {
+		parts = append(parts, "gravity")
+	}
+
+	if r.Wrap {
+		parts = append(parts, "wrap")
+	}
+
+	if r.Unbounded {
+		parts = append(parts, "unbounded")
+	}
+
+	if r.Misere {
+		parts = append(parts, "misere")
+	}
+
+	if r.Capture != 0 {
+		parts = append(parts, fmt.Sprintf("capture%d", r.Capture))
+	}
+
+	if r.Ultimate {
+		parts = append(parts, "ultimate")
+	}
+
+	if r.Stones != 0 || r.FirstStones != 0 {
+		parts = append(parts, fmt.Sprintf("stones%d/%d", max(1, r.Stones), max(1, r.FirstStones)))
+	}
+
+	if r.Players != 0 {
+		parts = append(parts, fmt.Sprintf("players%d", r.Players))
+	}
+
+	if r.Opening != NoOpening {
+		parts = append(parts, r.Opening.String())
+	}
+
+	return strings.Join(parts, "+")
+}
+
+// ParseRules reads rules written by Rules.String.
+func ParseRules(s string) (Rules, error) {
+	parts := strings.Split(s, "+")
+	set, err := ParseRuleSet(parts[0])
+	if err != nil {
+		return Rules{}, err
+	}
+
+	r := Rules{Set: set}
+	for _, part := range parts[1:] {
+		switch {
+		case part == "gravity":
+			r.Gravity = true
+		case part == "wrap":
+			r.Wrap = true
+		case part == "unbounded":
+			r.Unbounded = true
+		case part == "misere":
+			r.Misere = true
+		case part == "ultimate":
+			r.Ultimate = true
+		case strings.HasPrefix(part, "stones"):
+			if _, err := fmt.Sscanf(part, "stones%d/%d", &r.Stones, &r.FirstStones); err != nil {
+				return Rules{}, fmt.Errorf("invalid variant %q", part)
+			}
+		case strings.HasPrefix(part, "capture"):
+			if _, err := fmt.Sscanf(part, "capture%d", &r.Capture); err != nil {
+				return Rules{}, fmt.Errorf("invalid variant %q", part)
+			}
+		case strings.HasPrefix(part, "players"):
+			if _, err := fmt.Sscanf(part, "players%d", &r.Players); err != nil {
+				return Rules{}, fmt.Errorf("invalid variant %q", part)
+			}
+		default:
+			o, err := ParseOpening(part)
+			if err != nil || o == NoOpening {
+				return Rules{}, fmt.Errorf("unknown variant %q", part)
+			}
+
+			r.Opening = o
+		}
+	}
+
+	return r, nil
+}
+
+type Option func(*Board)
+
+// WithRules replaces all of the rules at once.
+func WithRules(r Rules) Option {
+	return func(b *Board) {
+		b.Rules = r
+	}
+}
+
+// WithGravity makes stones fall to the lowest empty cell of their column.
+func WithGravity() Option {
+	return func(b *Board) {
+		b.Rules.Gravity = true
+	}
+}
+
+// WithStonesPerTurn places p stones each turn, except the first which places
+// q, as in Connect(m,n,k,p,q).
+func WithStonesPerTurn(p, q int) Option {
+	return func(b *Board) {
+		b.Rules.Stones = p
+		b.Rules.FirstStones = q
+	}
+}
+
+func WithWrap() Option {
+	return func(b *Board) {
+		b.Rules.Wrap = true
+	}
+}
+
+func WithPlayers(n int) Option {
+	return func(b *Board) {
+		b.Rules.Players = n
+	}
+}
+
+func WithRuleSet(r RuleSet) Option {
+	return func(b *Board) {
+		b.Rules.Set = r
+	}
+}
+
+// IsLegal reports whether the player to move may play idx.
+func (b *Board) IsLegal(idx int) bool {
+	return b.isLegalFor(idx, b.ToMove())
+}
+
+func (b *Board) isLegalFor(idx int, p Player) bool {
+	if !b.validIdx(idx) || b.At(idx) != Empty {
+		return false
+	}
+
+	if b.Rules.Gravity {
+		below := idx + b.W
+		if below < len(b.Cells) && b.Cells[below] == Empty {
+			return false
+		}
+	}
+
+	if b.Rules.Set == Renju && p == P1 && b.Forbidden(idx) {
+		return false
+	}
+
+	if b.Rules.Ultimate && !b.ultimateLegal(idx) {
+		return false
+	}
+
+	return true
+}
+
+// Drop returns the cell a stone dropped in column x lands on.
+func (b *Board) Drop(x int) (int, bool) {
+	if x < 0 || x >= b.W {
+		return -1, false
+	}
+
+	for y := b.H - 1; y >= 0; y-- {
+		idx := b.GetIdx(x, y)
+		if b.Cells[idx] == Empty {
+			return idx, true
+		}
+	}
+
+	return -1, false
+}
+
+func (b *Board) wins(run int, p Player) bool {
+	switch b.Rules.Set {
+	case Exact:
+		return run == b.K
+	case Renju:
+		if p == P1 {
+			return run == b.K
+		}
+	}
+
+	return run >= b.K
+}
+
+// Forbidden reports whether playing idx would give P1 a double three, a
+// double four or an overline under renju rules. Completing a winning run is
+// never forbidden.
+func (b *Board) Forbidden(idx int) bool {
+	if b.At(idx) != Empty || !b.mayBeForbidden(idx) {
+		return false
+	}
+
+	b.put(idx, P1)
+	defer b.put(idx, Empty)
+
+	overline := false
+	fours, threes := 0, 0
+	for _, d := range directions {
+		back, fwd := b.run(idx, d, P1)
+		n := back + fwd + 1
+		if n == b.K {
+			return false
+		}
+
+		if n > b.K {
+			overline = true
+			continue
+		}
+
+		if f := b.fours(idx, d); f > 0 {
+			fours += f
+			continue
+		}
+
+		if b.openThree(idx, d) {
+			threes++
+		}
+	}
+
+	return overline || fours >= 2 || threes >= 2
+}
+
+// mayBeForbidden cheaply rules out cells that do not have enough P1 stones
+// around them to form any forbidden shape.
+func (b *Board) mayBeForbidden(idx int) bool {
+	lines := 0
+	for _, d := range directions {
+		stones := 0
+		for _, dir := range []int{-1, 1} {
+			for i := 1; i < b.K; i++ {
+				nidx, ok := b.step(idx, d, dir*i)
+				if !ok {
+					break
+				}
+
+				if b.At(nidx) == P1 {
+					stones++
+				}
+			}
+		}
+
+		// A single line can hold an overline or two fours, like X_XXX_X.
+		if stones >= b.K-1 {
+			return true
+		}
+
+		if stones >= b.K-3 {
+			lines++
+		}
+	}
+
+	return lines >= 2
+}
+
+// fives returns the empty cells on the line through idx that would complete a
+// winning run for P1 containing idx.
+func (b *Board) fives(idx int, d Dir) []int {
+	var cells []int
+	for _, dir := range []int{-1, 1} {
+		for i := 1; i < b.K; i++ {
+			nidx, ok := b.step(idx, d, dir*i)
+			if !ok {
+				break
+			}
+
+			c := b.At(nidx)
+			if c == P1 {
+				continue
+			}
+
+			if c != Empty {
+				break
+			}
+
+			b.put(nidx, P1)
+			back, fwd := b.run(nidx, d, P1)
+			b.put(nidx, Empty)
+
+			reach := fwd
+			if dir > 0 {
+				reach = back
+			}
+
+			if back+fwd+1 == b.K && reach >= i {
+				cells = append(cells, nidx)
+			}
+
+			break
+		}
+	}
+
+	return cells
+}
+
+func (b *Board) fours(idx int, d Dir) int {
+	cells := b.fives(idx, d)
+	if len(cells) < 2 {
+		return len(cells)
+	}
+
+	// Both ends of the same straight four only count once.
+	back, fwd := b.run(idx, d, P1)
+	start, _ := b.step(idx, d, -back-1)
+	end, _ := b.step(idx, d, fwd+1)
+	if back+fwd+1 == b.K-1 && cells[0] == start && cells[1] == end {
+		return 1
+	}
+
+	return len(cells)
+}
+
+// openThree reports whether a single P1 stone on the line through idx can turn
+// it into a straight four, a run of K-1 that can be completed at either end.
+func (b *Board) openThree(idx int, d Dir) bool {
+	for _, dir := range []int{-1, 1} {
+		for i := 1; i < b.K; i++ {
+			nidx, ok := b.step(idx, d, dir*i)
+			if !ok {
+				break
+			}
+
+			c := b.At(nidx)
+			if c == P1 {
+				continue
+			}
+
+			if c != Empty {
+				break
+			}
+
+			b.put(nidx, P1)
+			straight := b.straightFour(idx, d)
+			b.put(nidx, Empty)
+
+			if straight {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (b *Board) straightFour(idx int, d Dir) bool {
+	back, fwd := b.run(idx, d, P1)
+	if back+fwd+1 != b.K-1 {
+		return false
+	}
+
+	for _, end := range []int{-back - 1, fwd + 1} {
+		eidx, ok := b.step(idx, d, end)
+		if !ok || b.At(eidx) != Empty {
+			return false
+		}
+
+		dir := 1
+		if end < 0 {
+			dir = -1
+		}
+
+		if beyond, ok := b.step(eidx, d, dir); ok && b.At(beyond) == P1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// turnAt returns which turn ply belongs to and how many stones of that turn
+// are left to place.
+func (b *Board) turnAt(ply int) (int, int) {
+	p := max(1, b.Rules.Stones)
+	q := max(1, b.Rules.FirstStones)
+	if ply < q {
+		return 0, q - ply
+	}
+
+	ply -= q
+	return 1 + ply/p, p - ply%p
+}
+
+// StonesLeft returns how many stones the player to move still has to place
+// this turn.
+func (b *Board) StonesLeft() int {
+	_, left := b.turnAt(b.Turn)
+	return left
+}
+
+// nextTurnStones returns how many stones the next player places on their turn.
+func (b *Board) nextTurnStones() int {
+	_, stones := b.turnAt(b.Turn + b.StonesLeft())
+	return stones
+}
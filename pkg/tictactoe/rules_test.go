@@ -0,0 +1,137 @@
+package tictactoe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenjuForbidden(t *testing.T) {
+	// Each shape is drawn around the middle of a 15x15 board, with "*" the
+	// cell X is about to play.
+	tests := []struct {
+		name      string
+		shape     []string
+		forbidden bool
+	}{
+		{"open three", []string{"..XX*.."}, false},
+		{"double three", []string{
+			"....X..",
+			"....X..",
+			"..XX*..",
+		}, true},
+		{"double four across lines", []string{
+			"....X..",
+			"....X..",
+			"....X..",
+			".XXX*..",
+		}, true},
+		{"double four in one line", []string{"X.X*X.X"}, true},
+		{"split twos in one line", []string{"XX.*.XX"}, false},
+		{"four and three", []string{
+			"....X..",
+			"....X..",
+			"..XX*..",
+			"....X..",
+		}, false},
+		{"overline", []string{"XXX*XX"}, true},
+		{"five", []string{"XXX*X"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(15, 15, 5, WithRules(Rules{Set: Renju}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			target := -1
+			for y, row := range tt.shape {
+				for x, c := range row {
+					idx := b.GetIdx(4+x, 4+y)
+					switch c {
+					case 'X':
+						b.put(idx, P1)
+					case '*':
+						target = idx
+					}
+				}
+			}
+
+			if got := b.Forbidden(target); got != tt.forbidden {
+				t.Errorf("Forbidden on\n%s\n= %t, expected %t", strings.Join(tt.shape, "\n"), got, tt.forbidden)
+			}
+		})
+	}
+}
+
+func TestKInRowOverline(t *testing.T) {
+	tests := []struct {
+		name string
+		set  RuleSet
+		p    Player
+		row  int
+		want bool
+	}{
+		{"freestyle five", Freestyle, P1, 5, true},
+		{"freestyle overline", Freestyle, P1, 6, true},
+		{"exact five", Exact, P1, 5, true},
+		{"exact overline", Exact, P1, 6, false},
+		{"exact overline of O", Exact, P2, 6, false},
+		{"renju overline", Renju, P1, 6, false},
+		{"renju overline of O", Renju, P2, 6, true},
+		{"renju four", Renju, P1, 4, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := make([]Move, tt.row)
+			for i := range row {
+				row[i] = Move{X: 2 + i, Y: 7}
+			}
+
+			g, err := New(15, 15, 5, WithRuleSet(tt.set), WithStones(tt.p, row...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := g.Board.KInRow(tt.p); got != tt.want {
+				t.Errorf("KInRow(%s) = %t, expected %t", tt.p.Mark(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadExactOverline(t *testing.T) {
+	g, err := New(7, 7, 4, WithRuleSet(Exact))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// X makes five on the top row, which does not win, and O then makes four.
+	b := g.Board
+	moves := []Move{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 4, Y: 0}, {X: 5, Y: 5}, {X: 3, Y: 0}, {X: 3, Y: 2}}
+	for _, m := range moves {
+		if err := g.PlayMove(b.moveIdx(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s := g.Status(); s.Outcome != Won || s.Winner != P2 {
+		t.Fatalf("game %s won by %s, expected O to win", s.Outcome, s.Winner.Mark())
+	}
+
+	data, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGame(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := loaded.Status().Winner; w != P2 {
+		t.Errorf("loaded game won by %s, expected O", w.Mark())
+	}
+}
@@ -0,0 +1,415 @@
+package tictactoe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// SaveVersion is the version of the save format written by Save. Games saved
+// before the format had a version are version 0.
+const SaveVersion = 1
+
+// ErrInvalidSave is wrapped by every error about a saved game that can not be
+// loaded.
+var ErrInvalidSave = errors.New("invalid saved game")
+
+// SaveError describes what is wrong with a saved game. Field names the part
+// of the save that is wrong.
+type SaveError struct {
+	Field string
+	Err   error
+}
+
+func (e *SaveError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrInvalidSave, e.Field, e.Err)
+}
+
+func (e *SaveError) Unwrap() []error {
+	return []error{ErrInvalidSave, e.Err}
+}
+
+func invalid(field, format string, args ...any) error {
+	return &SaveError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// VersionError is returned for games saved in a newer format than this build
+// knows.
+type VersionError struct {
+	Version int
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("saved game has version %d, newest supported is %d", e.Version, SaveVersion)
+}
+
+// migrations[v] turns a save of version v into one of version v+1. Saves are
+// migrated as plain JSON objects, so old fields can be read after the Go types
+// have moved on.
+var migrations = []func(save map[string]any) error{
+	migrateUnversioned,
+}
+
+// migrateUnversioned handles saves from before the format had a version. The
+// oldest of them have square boards with a single size N.
+func migrateUnversioned(save map[string]any) error {
+	board, ok := save["Board"].(map[string]any)
+	if !ok {
+		return invalid("Board", "missing")
+	}
+
+	delete(board, "LastMoveUndo")
+
+	n, ok := board["N"]
+	if !ok {
+		return nil
+	}
+
+	if _, ok := board["W"]; !ok {
+		board["W"], board["H"] = n, n
+	}
+
+	delete(board, "N")
+
+	// Those boards only had Turn set by the bot, before its own moves, so it
+	// is counted from the stones instead. X always went first, so whoever has
+	// the odd stone out placed the last one.
+	cells, _ := board["Cells"].([]any)
+	stones := 0
+	for _, c := range cells {
+		if n, ok := c.(json.Number); ok && n.String() != "0" {
+			stones++
+		}
+	}
+
+	board["Turn"] = stones
+
+	last := json.Number(strconv.Itoa(int(P2)))
+	if stones%2 == 1 {
+		last = json.Number(strconv.Itoa(int(P1)))
+	}
+
+	// LastMove is kept unless an undo left it on a cell without the last
+	// player's stone.
+	lastMove := -1
+	if n, ok := board["LastMove"].(json.Number); ok {
+		if i, err := n.Int64(); err == nil && i >= 0 && int(i) < len(cells) && cells[i] == last {
+			lastMove = int(i)
+		}
+	}
+
+	for i := len(cells) - 1; i >= 0 && lastMove < 0; i-- {
+		if cells[i] == last {
+			lastMove = i
+		}
+	}
+
+	board["LastMove"] = max(0, lastMove)
+	return nil
+}
+
+// LoadGame loads a game written by Save, or by an older build, and checks
+// that it is a position that could have been played.
+func LoadGame(data []byte) (*Game, error) {
+	// Numbers are kept as they are, as hashes do not fit in a float64.
+	var save map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&save); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	version := 0
+	if v, ok := save["Version"]; ok {
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, invalid("Version", "%v is not a number", v)
+		}
+
+		i, err := n.Int64()
+		if err != nil || i < 0 {
+			return nil, invalid("Version", "%v is not a version", v)
+		}
+
+		version = int(i)
+	}
+
+	if version > SaveVersion {
+		return nil, &VersionError{Version: version}
+	}
+
+	for v := version; v < SaveVersion; v++ {
+		if err := migrations[v](save); err != nil {
+			return nil, err
+		}
+	}
+
+	delete(save, "Version")
+	data, err := json.Marshal(save)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	g := &Game{}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	if err := g.validateShape(); err != nil {
+		return nil, err
+	}
+
+	g.Board.game = g
+	g.Board.init()
+
+	if err := g.validatePosition(); err != nil {
+		return nil, err
+	}
+
+	var status struct{ Status *Status }
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSave, err)
+	}
+
+	if err := g.restoreStatus(status.Status); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// restoreStatus takes over how a saved game ended. Games that ended on the
+// board end the same way again when loaded, so only the winner is checked.
+// Saves from before the status was kept have none.
+func (g *Game) restoreStatus(s *Status) error {
+	if s == nil {
+		return nil
+	}
+
+	switch s.Outcome {
+	case Resigned, TimedOut, Forfeited:
+		if s.Ply != g.Board.Turn {
+			return invalid("Status", "ended on ply %d, but %d plies were played", s.Ply, g.Board.Turn)
+		}
+
+		if err := g.end(s.Outcome, s.Loser); err != nil {
+			return &SaveError{Field: "Status", Err: err}
+		}
+	case Won:
+		if g.Status().Winner != s.Winner {
+			return invalid("Status", "won by %s, which the board does not show", s.Winner.Mark())
+		}
+	}
+
+	return nil
+}
+
+// Save writes the game in the current save format, along with its status.
+func (g *Game) Save() ([]byte, error) {
+	if g.Board == nil {
+		return nil, errors.New("game has no board")
+	}
+
+	save := struct {
+		Version int
+		*Game
+		Status Status
+	}{SaveVersion, g, g.Status()}
+
+	return json.MarshalIndent(save, "", "\t")
+}
+
+// Validate checks that the game is consistent: the board fits its rules, the
+// history leads to the stones on the board, the hash matches them and at most
+// one player has won. Errors are *SaveError.
+func (g *Game) Validate() error {
+	if err := g.validateShape(); err != nil {
+		return err
+	}
+
+	return g.validatePosition()
+}
+
+// validateShape checks what has to hold before the board can be set up.
+func (g *Game) validateShape() error {
+	b := g.Board
+	if b == nil {
+		return invalid("Board", "missing")
+	}
+
+	if err := b.validateRules(); err != nil {
+		return &SaveError{Field: "Rules", Err: err}
+	}
+
+	if b.Rules.Unbounded {
+		for idx := range b.Stones {
+			if !b.validIdx(idx) {
+				return invalid("Stones", "cell %d is outside the board", idx)
+			}
+		}
+
+		return nil
+	}
+
+	if want := b.W * b.H * b.depth(); len(b.Cells) != want {
+		return invalid("Cells", "board has %d cells, expected %dx%dx%d", len(b.Cells), b.W, b.H, b.depth())
+	}
+
+	if len(g.ZobristKeys) != len(b.Cells) {
+		return invalid("ZobristKeys", "%d keys for %d cells", len(g.ZobristKeys), len(b.Cells))
+	}
+
+	for i, keys := range g.ZobristKeys {
+		if len(keys) < len(b.Players()) {
+			return invalid("ZobristKeys", "cell %d has keys for %d players, expected %d", i, len(keys), len(b.Players()))
+		}
+	}
+
+	return nil
+}
+
+// validatePosition checks the stones on a board that has been set up.
+func (g *Game) validatePosition() error {
+	b := g.Board
+
+	blocked := map[int]bool{}
+	if b.Setup != nil {
+		for _, m := range b.Setup.Blocked {
+			blocked[b.moveIdx(m)] = true
+		}
+	}
+
+	for idx, p := range b.occupied() {
+		switch {
+		case p == Blocked && !blocked[idx]:
+			return invalid("Cells", "%s is blocked but not in the setup", b.FormatMove(idx))
+		case p != Blocked && !slices.Contains(b.Players(), p):
+			return invalid("Cells", "%s holds %d, who is not playing", b.FormatMove(idx), p)
+		}
+	}
+
+	for idx := range blocked {
+		if b.At(idx) != Blocked {
+			return invalid("Cells", "%s is blocked in the setup but not on the board", b.FormatMove(idx))
+		}
+	}
+
+	if h := b.RecomputeHash(); b.Hash != h {
+		return invalid("Hash", "%#x does not match the stones, expected %#x", b.Hash, h)
+	}
+
+	if b.Turn < 0 {
+		return invalid("Turn", "%d is negative", b.Turn)
+	}
+
+	// Games saved before the history was kept only have the stones to go by.
+	if len(b.History) == 0 && b.Turn > b.setupTurn() && b.Rules.Capture == 0 {
+		if err := b.validateStones(); err != nil {
+			return err
+		}
+	} else if err := b.validateHistory(); err != nil {
+		return err
+	}
+
+	winners := 0
+	for _, p := range b.Players() {
+		if b.KInRow(p) {
+			winners++
+		}
+	}
+
+	if winners > 1 {
+		return invalid("Cells", "%d players have a row of %d", winners, b.K)
+	}
+
+	if g.Phase < PhasePlay || g.Phase > PhaseChoose {
+		return invalid("Phase", "unknown phase %d", g.Phase)
+	}
+
+	for i, ply := range g.Undone {
+		if !slices.Contains(b.Players(), ply.Player) || !b.validIdx(ply.Idx) {
+			return invalid(fmt.Sprintf("Undone[%d]", i), "%s on %d can not be played", ply.Player.Mark(), ply.Idx)
+		}
+	}
+
+	if len(g.Names) > len(b.Players()) {
+		return invalid("Names", "%d names for %d players", len(g.Names), len(b.Players()))
+	}
+
+	return nil
+}
+
+// validateHistory replays the history from the setup and checks that every
+// ply was played in turn, before the game was over, and leads to the board.
+func (b *Board) validateHistory() error {
+	if len(b.History) != b.Turn-b.setupTurn() {
+		return invalid("History", "%d plies for turn %d", len(b.History), b.Turn)
+	}
+
+	t := b.Clone()
+	t.reset()
+	for i, ply := range b.History {
+		field := fmt.Sprintf("History[%d]", i)
+		if t.CheckWinner() != Empty {
+			return invalid(field, "played after the game was over")
+		}
+
+		if ply.Player != t.ToMove() {
+			return invalid(field, "%s is not to move", ply.Player.Mark())
+		}
+
+		if err := t.ApplyMove(ply.Idx, ply.Player); err != nil {
+			return &SaveError{Field: field, Err: err}
+		}
+
+		if t.Hash != ply.Hash {
+			return invalid(field, "hash %#x does not match the position, expected %#x", ply.Hash, t.Hash)
+		}
+	}
+
+	if !slices.Equal(t.Cells, b.Cells) || !maps.Equal(t.Stones, b.Stones) {
+		return invalid("Cells", "the stones do not match the history")
+	}
+
+	if t.LastMove != b.LastMove {
+		return invalid("LastMove", "%d is not the last ply of the history", b.LastMove)
+	}
+
+	return nil
+}
+
+// validateStones checks that every player has as many stones on the board as
+// they placed in the plies played so far.
+func (b *Board) validateStones() error {
+	players := b.Players()
+	want := map[Player]int{}
+	if b.Setup != nil {
+		for p, cells := range b.Setup.Stones {
+			want[p] += len(cells)
+		}
+	}
+
+	for ply := b.setupTurn(); ply < b.Turn; ply++ {
+		turn, _ := b.turnAt(ply)
+		want[players[turn%len(players)]]++
+	}
+
+	have := map[Player]int{}
+	for _, p := range b.occupied() {
+		if p.IsStone() {
+			have[p]++
+		}
+	}
+
+	for _, p := range players {
+		if have[p] != want[p] {
+			return invalid("Cells", "%s has %d stones after %d plies, expected %d", p.Mark(), have[p], b.Turn, want[p])
+		}
+	}
+
+	return nil
+}
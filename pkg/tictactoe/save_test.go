@@ -0,0 +1,223 @@
+package tictactoe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// baselineSave is shaped like a game saved before saves had a version: a
+// square board of size N with X on b2 and c1 and O on a3, keys of 2i+1 and
+// 2i+2 for X and O on cell i, and Turn and LastMove as the old build left them.
+func baselineSave(turn, lastMove int) []byte {
+	return fmt.Appendf(nil, `{
+	"Board": {
+		"N": 3,
+		"K": 3,
+		"Cells": [-1, 0, 0, 0, 1, 0, 0, 0, 1],
+		"LastMove": %d,
+		"LastMoveUndo": 0,
+		"Hash": 26,
+		"Turn": %d
+	},
+	"ZobristKeys": [[1, 2], [3, 4], [5, 6], [7, 8], [9, 10], [11, 12], [13, 14], [15, 16], [17, 18]]
+}`, lastMove, turn)
+}
+
+func TestLoadBaselineSave(t *testing.T) {
+	tests := []struct {
+		name           string
+		turn, lastMove int
+	}{
+		{"turn set by the bot", 1, 8},
+		{"turn never set", 0, 8},
+		{"last move undone", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := LoadGame(baselineSave(tt.turn, tt.lastMove))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			if b.W != 3 || b.H != 3 {
+				t.Errorf("size %dx%d, expected 3x3", b.W, b.H)
+			}
+
+			if b.Turn != 3 || b.ToMove() != P2 {
+				t.Errorf("turn %d with %s to move, expected 3 with O", b.Turn, b.ToMove().Mark())
+			}
+
+			if b.LastMove != 8 {
+				t.Errorf("last move %d, expected 8", b.LastMove)
+			}
+
+			if err := g.PlayMove(b.GetIdx(1, 0)); err != nil {
+				t.Errorf("playing on after loading: %v", err)
+			}
+		})
+	}
+}
+
+// saveMap saves the game after moves and returns the save as plain JSON.
+func saveMap(t *testing.T, moves ...int) map[string]any {
+	t.Helper()
+
+	g, err := New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, idx := range moves {
+		if err := g.PlayMove(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var save map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&save); err != nil {
+		t.Fatal(err)
+	}
+
+	return save
+}
+
+// setCells puts cells on the board of a save and updates its hash to match.
+func setCells(save map[string]any, cells ...int) uint64 {
+	board := save["Board"].(map[string]any)
+	keys := save["ZobristKeys"].([]any)
+
+	var hash uint64
+	values := make([]any, len(cells))
+	for i, p := range cells {
+		values[i] = json.Number(strconv.Itoa(p))
+		if p != 0 {
+			key, _ := strconv.ParseUint(string(keys[i].([]any)[Player(p).Idx()].(json.Number)), 10, 64)
+			hash ^= key
+		}
+	}
+
+	board["Cells"] = values
+	board["Hash"] = json.Number(strconv.FormatUint(hash, 10))
+	return hash
+}
+
+func TestLoadGameInvalid(t *testing.T) {
+	board := func(save map[string]any) map[string]any {
+		return save["Board"].(map[string]any)
+	}
+
+	history := func(save map[string]any, i int) map[string]any {
+		return board(save)["History"].([]any)[i].(map[string]any)
+	}
+
+	tests := []struct {
+		name   string
+		moves  []int
+		mutate func(save map[string]any)
+		field  string
+	}{
+		{"version is not a number", nil, func(s map[string]any) { s["Version"] = "one" }, "Version"},
+		{"negative version", nil, func(s map[string]any) { s["Version"] = -1 }, "Version"},
+		{"no board", nil, func(s map[string]any) { delete(s, "Board") }, "Board"},
+		{"row longer than the board", nil, func(s map[string]any) { board(s)["K"] = 4 }, "Rules"},
+		{"missing cells", nil, func(s map[string]any) { board(s)["Cells"] = board(s)["Cells"].([]any)[:8] }, "Cells"},
+		{"missing keys", nil, func(s map[string]any) { s["ZobristKeys"] = s["ZobristKeys"].([]any)[:8] }, "ZobristKeys"},
+		{"keys for one player", nil, func(s map[string]any) { s["ZobristKeys"].([]any)[0] = []any{1} }, "ZobristKeys"},
+		{"blocked cell outside the setup", []int{4, 0}, func(s map[string]any) { board(s)["Cells"].([]any)[8] = Blocked }, "Cells"},
+		{"stone of a player not playing", []int{4, 0}, func(s map[string]any) { board(s)["Cells"].([]any)[8] = P3 }, "Cells"},
+		{"wrong hash", []int{4, 0}, func(s map[string]any) { board(s)["Hash"] = 1 }, "Hash"},
+		{"negative turn", nil, func(s map[string]any) { board(s)["Turn"] = -1 }, "Turn"},
+		{"history shorter than the turn", []int{4, 0}, func(s map[string]any) { board(s)["Turn"] = 3 }, "History"},
+		{"ply out of turn", []int{4, 0}, func(s map[string]any) { history(s, 0)["Player"] = P2 }, "History[0]"},
+		{"ply with the wrong hash", []int{4, 0}, func(s map[string]any) { history(s, 1)["Hash"] = 1 }, "History[1]"},
+		{"last move not the last ply", []int{4, 0}, func(s map[string]any) { board(s)["LastMove"] = 4 }, "LastMove"},
+		{"ply after the game was over", []int{0, 3, 1, 4, 2}, func(s map[string]any) {
+			hash := setCells(s, 1, 1, 1, -1, -1, -1, 0, 0, 0)
+			b := board(s)
+			b["History"] = append(b["History"].([]any), map[string]any{"Player": P2, "Idx": 5, "Hash": hash})
+			b["Turn"], b["LastMove"] = 6, 5
+			delete(s, "Status")
+		}, "History[5]"},
+		{"two rows", nil, func(s map[string]any) {
+			setCells(s, 1, 1, 1, -1, -1, -1, 0, 0, 0)
+			board(s)["Turn"], board(s)["LastMove"] = 6, 5
+		}, "Cells"},
+		{"unknown phase", nil, func(s map[string]any) { s["Phase"] = 7 }, "Phase"},
+		{"unplayable undone ply", nil, func(s map[string]any) { s["Undone"] = []any{map[string]any{"Player": P3, "Idx": 0}} }, "Undone[0]"},
+		{"too many names", nil, func(s map[string]any) { s["Names"] = []string{"a", "b", "c"} }, "Names"},
+		{"won without a row", []int{4, 0}, func(s map[string]any) { s["Status"] = map[string]any{"Outcome": Won, "Winner": P1, "Ply": 2} }, "Status"},
+		{"resigned on another ply", []int{4, 0}, func(s map[string]any) { s["Status"] = map[string]any{"Outcome": Resigned, "Loser": P1, "Ply": 1} }, "Status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			save := saveMap(t, tt.moves...)
+			tt.mutate(save)
+
+			data, err := json.Marshal(save)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = LoadGame(data)
+			var saveErr *SaveError
+			if !errors.As(err, &saveErr) || !errors.Is(err, ErrInvalidSave) {
+				t.Fatalf("LoadGame: %v, expected a *SaveError", err)
+			}
+
+			if saveErr.Field != tt.field {
+				t.Errorf("LoadGame: %v, expected it to be about %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestLoadGameVersions(t *testing.T) {
+	save := saveMap(t, 4, 0)
+
+	save["Version"] = SaveVersion + 1
+	data, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versionErr *VersionError
+	if _, err := LoadGame(data); !errors.As(err, &versionErr) || versionErr.Version != SaveVersion+1 {
+		t.Errorf("LoadGame of a newer save: %v, expected a *VersionError", err)
+	}
+
+	// Saves from before the version was written, but after boards had a width
+	// and height, only lose the fields that are gone.
+	delete(save, "Version")
+	delete(save, "Status")
+	save["Board"].(map[string]any)["LastMoveUndo"] = 4
+	if data, err = json.Marshal(save); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := LoadGame(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Board.Turn != 2 || g.Board.LastMove != 0 || len(g.Board.History) != 2 {
+		t.Errorf("loaded turn %d, last move %d and %d plies, expected 2, 0 and 2", g.Board.Turn, g.Board.LastMove, len(g.Board.History))
+	}
+
+	if _, err := LoadGame([]byte("{")); !errors.Is(err, ErrInvalidSave) {
+		t.Errorf("LoadGame of broken JSON: %v, expected %v", err, ErrInvalidSave)
+	}
+}
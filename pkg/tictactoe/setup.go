@@ -0,0 +1,202 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Setup is the position a game starts from before any moves are made. Blocked
+// cells can not be played and are not part of any line. Stones are placed
+// before the first move, as a handicap or to set up a puzzle, and can not be
+// taken back.
+//
+// A game set up from a position part way through also carries on from it:
+// Turn is how many plies were played before the setup, LastMove the last of
+// them and Captures the pairs each player had captured, in turn order.
+type Setup struct {
+	Blocked []Move            `json:",omitempty"`
+	Stones  map[Player][]Move `json:",omitempty"`
+
+	Turn     int   `json:",omitempty"`
+	LastMove *Move `json:",omitempty"`
+	Captures []int `json:",omitempty"`
+}
+
+// WithBlocked blocks the given cells for the whole game.
+func WithBlocked(cells ...Move) Option {
+	return func(b *Board) {
+		if b.Setup == nil {
+			b.Setup = &Setup{}
+		}
+
+		b.Setup.Blocked = append(b.Setup.Blocked, cells...)
+	}
+}
+
+// WithStones places stones of p on the given cells before the first move.
+func WithStones(p Player, cells ...Move) Option {
+	return func(b *Board) {
+		if b.Setup == nil {
+			b.Setup = &Setup{}
+		}
+
+		if b.Setup.Stones == nil {
+			b.Setup.Stones = map[Player][]Move{}
+		}
+
+		b.Setup.Stones[p] = append(b.Setup.Stones[p], cells...)
+	}
+}
+
+// WithSetup starts the game from s, replacing any blocked cells and stones
+// given before.
+func WithSetup(s Setup) Option {
+	return func(b *Board) {
+		b.Setup = &s
+	}
+}
+
+// IsStone reports whether p is a player's stone rather than an empty or
+// blocked cell.
+func (p Player) IsStone() bool {
+	return p.Idx() >= 0
+}
+
+func (s *Setup) validate(b *Board) error {
+	seen := map[Move]bool{}
+	check := func(m Move) error {
+		if !b.InBounds3(m.X, m.Y, m.Z) {
+			return fmt.Errorf("setup cell %+v is outside the board", m)
+		}
+
+		if seen[m] {
+			return fmt.Errorf("setup cell %+v is used twice", m)
+		}
+
+		seen[m] = true
+		return nil
+	}
+
+	for _, m := range s.Blocked {
+		if err := check(m); err != nil {
+			return err
+		}
+	}
+
+	for p, cells := range s.Stones {
+		if !slices.Contains(b.Players(), p) {
+			return fmt.Errorf("setup stones for %d, who is not playing", p)
+		}
+
+		for _, m := range cells {
+			if err := check(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Stones fall onto the floor or other stones, and blocked cells would give
+	// a column more than one cell to land on.
+	if b.Rules.Gravity {
+		if len(s.Blocked) > 0 {
+			return errors.New("blocked cells can not be combined with gravity")
+		}
+
+		for _, cells := range s.Stones {
+			for _, m := range cells {
+				below := Move{X: m.X, Y: m.Y + 1}
+				if m.Y < b.H-1 && !seen[below] {
+					return fmt.Errorf("setup stone %+v is not resting on anything", m)
+				}
+			}
+		}
+	}
+
+	if s.Turn < 0 {
+		return fmt.Errorf("setup turn %d is negative", s.Turn)
+	}
+
+	if s.LastMove != nil {
+		found := false
+		for _, cells := range s.Stones {
+			found = found || slices.Contains(cells, *s.LastMove)
+		}
+
+		if !found || s.Turn == 0 {
+			return fmt.Errorf("setup last move %+v is not a stone played before the setup", *s.LastMove)
+		}
+	}
+
+	if len(s.Captures) > len(b.Players()) || slices.ContainsFunc(s.Captures, func(n int) bool { return n < 0 }) {
+		return fmt.Errorf("invalid setup captures %v", s.Captures)
+	}
+
+	return nil
+}
+
+// applySetup puts the setup on an empty board.
+func (b *Board) applySetup() {
+	if b.Setup == nil {
+		return
+	}
+
+	for _, m := range b.Setup.Blocked {
+		b.put(b.moveIdx(m), Blocked)
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(b.Setup.Stones)) {
+		for _, m := range b.Setup.Stones[p] {
+			idx := b.moveIdx(m)
+			b.put(idx, p)
+			b.Hash ^= b.key(idx, p)
+		}
+	}
+
+	b.Turn = b.Setup.Turn
+	b.LastMove = b.setupLastMove()
+}
+
+// setupTurn returns how many plies were played before the setup.
+func (b *Board) setupTurn() int {
+	if b.Setup == nil {
+		return 0
+	}
+
+	return b.Setup.Turn
+}
+
+// setupLastMove returns the last move played before the setup, or 0 if there
+// was none.
+func (b *Board) setupLastMove() int {
+	if b.Setup == nil || b.Setup.LastMove == nil {
+		return 0
+	}
+
+	return b.moveIdx(*b.Setup.LastMove)
+}
+
+// reset takes the board back to its setup, before any moves were made.
+func (b *Board) reset() {
+	clear(b.Cells)
+	clear(b.Stones)
+	b.Hash, b.Turn, b.LastMove = 0, 0, 0
+	b.History = b.History[:0]
+	b.applySetup()
+	b.init()
+}
+
+// setupStones returns how many stones the setup placed.
+func (b *Board) setupStones() int {
+	if b.Setup == nil {
+		return 0
+	}
+
+	n := 0
+	for _, cells := range b.Setup.Stones {
+		n += len(cells)
+	}
+
+	return n
+}
@@ -0,0 +1,43 @@
+package tictactoe
+
+import "testing"
+
+func TestSetupGravity(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		valid bool
+	}{
+		{"stones on the floor", []Option{WithStones(P1, Move{X: 0, Y: 2}), WithStones(P2, Move{X: 0, Y: 1})}, true},
+		{"floating stone", []Option{WithStones(P1, Move{X: 0, Y: 1})}, false},
+		{"blocked cell", []Option{WithBlocked(Move{X: 0, Y: 1})}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(3, 3, 3, append(tt.opts, WithGravity())...)
+			if (err == nil) != tt.valid {
+				t.Fatalf("New: %v, expected valid %t", err, tt.valid)
+			}
+
+			if err != nil {
+				return
+			}
+
+			// Every legal move is the cell its column drops to.
+			b := g.Board
+			for _, idx := range b.LegalMoves() {
+				if drop, ok := b.Drop(b.GetMove(idx).X); !ok || drop != idx {
+					t.Errorf("legal move %d, but its column drops to %d", idx, drop)
+				}
+			}
+
+			for idx := range b.Cells {
+				drop, _ := b.Drop(b.GetMove(idx).X)
+				if b.IsLegal(idx) != (drop == idx) {
+					t.Errorf("IsLegal(%d) = %t, but its column drops to %d", idx, b.IsLegal(idx), drop)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sgfLetters are the coordinates SGF uses, which limits boards to 52x52.
+const sgfLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// SGF writes the game as an SGF gomoku record (GM[4]). Besides the standard
+// properties it stores K in K[], the opening choices in OC[] and blocked cells
+// in BL[]. Pre-placed stones use the standard AB[] and AW[].
+func (g *Game) SGF() ([]byte, error) {
+	r, err := g.record()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.D > 1 {
+		return nil, errors.New("SGF has no way to write boards with layers")
+	}
+
+	if r.W > len(sgfLetters) || r.H > len(sgfLetters) {
+		return nil, fmt.Errorf("board %dx%d is too large for SGF", r.W, r.H)
+	}
+
+	s := strings.Builder{}
+	s.WriteString("(;GM[4]FF[4]CA[UTF-8]AP[ticntacntoen]")
+	if r.W == r.H {
+		fmt.Fprintf(&s, "SZ[%d]", r.W)
+	} else {
+		fmt.Fprintf(&s, "SZ[%d:%d]", r.W, r.H)
+	}
+
+	fmt.Fprintf(&s, "K[%d]RU[%s]", r.K, r.Rules)
+
+	if r.Names[0] != "" {
+		fmt.Fprintf(&s, "PB[%s]", sgfEscape(r.Names[0]))
+	}
+
+	if r.Names[1] != "" {
+		fmt.Fprintf(&s, "PW[%s]", sgfEscape(r.Names[1]))
+	}
+
+	// Games not won on the board say how they were won, like "B+R" for a
+	// resignation.
+	how := outcomeCodes[r.Outcome]
+	switch {
+	case r.Winner == P1:
+		fmt.Fprintf(&s, "RE[B+%s]", how)
+	case r.Winner == P2:
+		fmt.Fprintf(&s, "RE[W+%s]", how)
+	case r.Draw:
+		s.WriteString("RE[0]")
+	}
+
+	if len(r.Choices) > 0 {
+		s.WriteString("OC")
+		for _, c := range r.Choices {
+			fmt.Fprintf(&s, "[%s]", choiceCodes[c])
+		}
+	}
+
+	if r.Setup != nil {
+		props := []struct {
+			id    string
+			cells []Move
+		}{
+			{"BL", r.Setup.Blocked},
+			{"AB", r.Setup.Stones[P1]},
+			{"AW", r.Setup.Stones[P2]},
+		}
+
+		for _, prop := range props {
+			if len(prop.cells) == 0 {
+				continue
+			}
+
+			s.WriteString(prop.id)
+			for _, m := range prop.cells {
+				fmt.Fprintf(&s, "[%s]", sgfPoint(m))
+			}
+		}
+	}
+
+	for _, ply := range r.Moves {
+		color := "B"
+		if ply.Player == P2 {
+			color = "W"
+		}
+
+		fmt.Fprintf(&s, "\n;%s[%s]", color, sgfPoint(Move{X: ply.Idx % r.W, Y: ply.Idx / r.W}))
+	}
+
+	s.WriteString(")\n")
+
+	return []byte(s.String()), nil
+}
+
+// LoadSGF reads a game written by Game.SGF. Only the main line of the record
+// is played, and records from other tools are read as freestyle five in a row
+// unless they say otherwise.
+func LoadSGF(data []byte) (*Game, error) {
+	p := &sgfParser{s: string(data)}
+	nodes, err := p.tree()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.New("sgf: empty game")
+	}
+
+	root := nodes[0]
+	if gm, ok := root["GM"]; ok && gm[0] != "4" {
+		return nil, fmt.Errorf("sgf: game type %s is not gomoku", gm[0])
+	}
+
+	r := &record{W: 15, H: 15, K: 5}
+	if sz, ok := root["SZ"]; ok {
+		w, h, square := strings.Cut(sz[0], ":")
+		r.W, err = strconv.Atoi(w)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid size %q", sz[0])
+		}
+
+		r.H = r.W
+		if square {
+			if r.H, err = strconv.Atoi(h); err != nil {
+				return nil, fmt.Errorf("sgf: invalid size %q", sz[0])
+			}
+		}
+	}
+
+	if k, ok := root["K"]; ok {
+		if r.K, err = strconv.Atoi(k[0]); err != nil {
+			return nil, fmt.Errorf("sgf: invalid K %q", k[0])
+		}
+	}
+
+	if ru, ok := root["RU"]; ok {
+		if r.Rules, err = ParseRules(strings.ToLower(ru[0])); err != nil {
+			return nil, fmt.Errorf("sgf: %w", err)
+		}
+	}
+
+	if pb, ok := root["PB"]; ok {
+		r.Names[0] = pb[0]
+	}
+
+	if pw, ok := root["PW"]; ok {
+		r.Names[1] = pw[0]
+	}
+
+	if re, ok := root["RE"]; ok {
+		color, how, won := strings.Cut(re[0], "+")
+		switch {
+		case won && color == "B":
+			r.Winner, r.Outcome = P1, parseOutcome(how)
+		case won && color == "W":
+			r.Winner, r.Outcome = P2, parseOutcome(how)
+		}
+	}
+
+	for _, code := range root["OC"] {
+		c, err := parseChoice(code)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: %w", err)
+		}
+
+		r.Choices = append(r.Choices, c)
+	}
+
+	for _, id := range []string{"BL", "AB", "AW"} {
+		for _, v := range root[id] {
+			m, err := r.parseSGFPoint(v)
+			if err != nil {
+				return nil, err
+			}
+
+			if r.Setup == nil {
+				r.Setup = &Setup{Stones: map[Player][]Move{}}
+			}
+
+			switch id {
+			case "BL":
+				r.Setup.Blocked = append(r.Setup.Blocked, m)
+			case "AB":
+				r.Setup.Stones[P1] = append(r.Setup.Stones[P1], m)
+			case "AW":
+				r.Setup.Stones[P2] = append(r.Setup.Stones[P2], m)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		for _, color := range []string{"B", "W"} {
+			player := P1
+			if color == "W" {
+				player = P2
+			}
+
+			for _, v := range node[color] {
+				m, err := r.parseSGFPoint(v)
+				if err != nil {
+					return nil, err
+				}
+
+				r.Moves = append(r.Moves, Ply{Player: player, Idx: m.Y*r.W + m.X})
+			}
+		}
+	}
+
+	return r.replay()
+}
+
+func sgfPoint(m Move) string {
+	return string([]byte{sgfLetters[m.X], sgfLetters[m.Y]})
+}
+
+func (r *record) parseSGFPoint(v string) (Move, error) {
+	if len(v) != 2 {
+		return Move{}, fmt.Errorf("sgf: invalid point %q", v)
+	}
+
+	x, y := strings.IndexByte(sgfLetters, v[0]), strings.IndexByte(sgfLetters, v[1])
+	if x < 0 || y < 0 || x >= r.W || y >= r.H {
+		return Move{}, fmt.Errorf("sgf: invalid point %q", v)
+	}
+
+	return Move{X: x, Y: y}, nil
+}
+
+func sgfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(s)
+}
+
+type sgfNode map[string][]string
+
+type sgfParser struct {
+	s string
+	i int
+}
+
+func (p *sgfParser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// tree reads a game tree and returns the nodes of its main line.
+func (p *sgfParser) tree() ([]sgfNode, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != '(' {
+		return nil, fmt.Errorf("sgf: expected '(' at %d", p.i)
+	}
+
+	p.i++
+
+	var nodes []sgfNode
+	variation := false
+	for {
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			return nil, errors.New("sgf: unexpected end of record")
+		}
+
+		switch p.s[p.i] {
+		case ';':
+			p.i++
+			node, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, node)
+		case '(':
+			sub, err := p.tree()
+			if err != nil {
+				return nil, err
+			}
+
+			if !variation {
+				nodes = append(nodes, sub...)
+				variation = true
+			}
+		case ')':
+			p.i++
+			return nodes, nil
+		default:
+			return nil, fmt.Errorf("sgf: unexpected %q at %d", p.s[p.i], p.i)
+		}
+	}
+}
+
+func (p *sgfParser) node() (sgfNode, error) {
+	node := sgfNode{}
+	for {
+		p.skipSpace()
+		start := p.i
+		for p.i < len(p.s) && (p.s[p.i] >= 'A' && p.s[p.i] <= 'Z' || p.s[p.i] >= 'a' && p.s[p.i] <= 'z') {
+			p.i++
+		}
+
+		if start == p.i {
+			return node, nil
+		}
+
+		// Old SGF versions allow lower case letters in property names, which
+		// are ignored.
+		id := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return -1
+			}
+
+			return r
+		}, p.s[start:p.i])
+
+		for {
+			p.skipSpace()
+			if p.i >= len(p.s) || p.s[p.i] != '[' {
+				break
+			}
+
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+
+			node[id] = append(node[id], v)
+		}
+
+		if len(node[id]) == 0 {
+			return nil, fmt.Errorf("sgf: property %s has no value", id)
+		}
+	}
+}
+
+func (p *sgfParser) value() (string, error) {
+	p.i++
+
+	v := strings.Builder{}
+	for ; p.i < len(p.s); p.i++ {
+		switch c := p.s[p.i]; c {
+		case '\\':
+			p.i++
+			if p.i < len(p.s) {
+				v.WriteByte(p.s[p.i])
+			}
+		case ']':
+			p.i++
+			return v.String(), nil
+		default:
+			v.WriteByte(c)
+		}
+	}
+
+	return "", errors.New("sgf: unterminated property value")
+}
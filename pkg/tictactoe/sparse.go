@@ -0,0 +1,167 @@
+package tictactoe
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Unbounded boards pack coordinates into a cell index so moves stay plain
+// ints. Coordinates range from -unboundedSpan to unboundedSpan-1 on each axis.
+const (
+	unboundedSpan   = 1 << 15
+	unboundedStride = 2 * unboundedSpan
+)
+
+// candidateRadius is how far from existing stones moves are offered on an
+// unbounded board.
+const candidateRadius = 2
+
+// WithUnbounded plays on an infinite plane instead of a W×H grid. Stones are
+// kept in a map and only cells near existing stones are offered as moves.
+func WithUnbounded() Option {
+	return func(b *Board) {
+		b.Rules.Unbounded = true
+	}
+}
+
+// coords returns the x and y of idx.
+func (b *Board) coords(idx int) (int, int) {
+	if b.Rules.Unbounded {
+		return idx%unboundedStride - unboundedSpan, idx/unboundedStride - unboundedSpan
+	}
+
+	return b.xList[idx], b.yList[idx]
+}
+
+func (b *Board) validIdx(idx int) bool {
+	if b.Rules.Unbounded {
+		return idx >= 0 && idx < unboundedStride*unboundedStride
+	}
+
+	return idx >= 0 && idx < len(b.Cells)
+}
+
+// At returns the stone at idx.
+func (b *Board) At(idx int) Player {
+	if b.Rules.Unbounded {
+		return b.Stones[idx]
+	}
+
+	return b.Cells[idx]
+}
+
+func (b *Board) put(idx int, p Player) {
+	if !b.Rules.Unbounded {
+		b.Cells[idx] = p
+		return
+	}
+
+	if p == Empty {
+		delete(b.Stones, idx)
+		return
+	}
+
+	b.Stones[idx] = p
+}
+
+// occupied yields every stone on the board.
+func (b *Board) occupied() iter.Seq2[int, Player] {
+	return func(yield func(int, Player) bool) {
+		if b.Rules.Unbounded {
+			for _, idx := range slices.Sorted(maps.Keys(b.Stones)) {
+				if !yield(idx, b.Stones[idx]) {
+					return
+				}
+			}
+
+			return
+		}
+
+		for idx, p := range b.Cells {
+			if p == Empty {
+				continue
+			}
+
+			if !yield(idx, p) {
+				return
+			}
+		}
+	}
+}
+
+// Bounds returns the smallest rectangle holding every stone. Bounded boards
+// always return the whole board.
+func (b *Board) Bounds() (minX, minY, maxX, maxY int) {
+	if !b.Rules.Unbounded {
+		return 0, 0, b.W - 1, b.H - 1
+	}
+
+	first := true
+	for idx := range b.Stones {
+		x, y := b.coords(idx)
+		if first {
+			minX, minY, maxX, maxY = x, y, x, y
+			first = false
+			continue
+		}
+
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+
+	return minX, minY, maxX, maxY
+}
+
+// updateNear keeps count of how many stones are within candidateRadius of
+// each cell.
+func (b *Board) updateNear(idx int, delta int) {
+	x, y := b.coords(idx)
+	for dy := -candidateRadius; dy <= candidateRadius; dy++ {
+		for dx := -candidateRadius; dx <= candidateRadius; dx++ {
+			if !b.InBounds(x+dx, y+dy) {
+				continue
+			}
+
+			nidx := b.GetIdx(x+dx, y+dy)
+			b.near[nidx] += delta
+			if b.near[nidx] == 0 {
+				delete(b.near, nidx)
+			}
+		}
+	}
+}
+
+func (b *Board) candidates() []int {
+	if len(b.near) == 0 {
+		origin := b.GetIdx(0, 0)
+		if _, ok := b.Stones[origin]; !ok {
+			return []int{origin}
+		}
+
+		// Only blocked cells so far, and one of them on the origin.
+		var moves []int
+		for dy := -candidateRadius; dy <= candidateRadius; dy++ {
+			for dx := -candidateRadius; dx <= candidateRadius; dx++ {
+				if idx := b.GetIdx(dx, dy); b.Stones[idx] == Empty {
+					moves = append(moves, idx)
+				}
+			}
+		}
+
+		slices.Sort(moves)
+		return moves
+	}
+
+	moves := make([]int, 0, len(b.near))
+	for idx := range b.near {
+		if _, ok := b.Stones[idx]; ok {
+			continue
+		}
+
+		moves = append(moves, idx)
+	}
+
+	slices.Sort(moves)
+	return moves
+}
@@ -0,0 +1,114 @@
+package tictactoe
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var errGameOver = errors.New("game is over")
+
+// Outcome is how a game stands or how it ended.
+type Outcome int8
+
+const (
+	Ongoing Outcome = iota
+	// Won is a game ended by a row, by captures or, under misère rules, by
+	// the other player making a row.
+	Won
+	// Drawn is a game where the board is full or no row can be made any more.
+	Drawn
+	Resigned
+	TimedOut
+	Forfeited
+)
+
+var outcomeNames = []string{"ongoing", "won", "drawn", "resigned", "timed out", "forfeited"}
+
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomeNames) {
+		return fmt.Sprintf("Outcome(%d)", o)
+	}
+
+	return outcomeNames[o]
+}
+
+// Status is the state of a game. Winner is who won, if anyone did, and Loser
+// who resigned, ran out of time or forfeited. Line is the row that ended the
+// game, which under misère rules is the loser's. Ply is how many plies were
+// played when the game ended, or so far while it is ongoing.
+type Status struct {
+	Outcome Outcome
+	Winner  Player `json:",omitempty"`
+	Loser   Player `json:",omitempty"`
+	Line    []int  `json:",omitempty"`
+	Ply     int
+}
+
+// Over reports whether the game has ended.
+func (s Status) Over() bool {
+	return s.Outcome != Ongoing
+}
+
+// Status returns how the game stands.
+func (g *Game) Status() Status {
+	if g.ended != nil {
+		return *g.ended
+	}
+
+	b := g.Board
+	s := Status{Ply: b.Turn}
+	if winner := b.CheckWinner(); winner != Empty {
+		s.Outcome, s.Winner = Won, winner
+
+		rowOwner := winner
+		if b.Rules.Misere {
+			rowOwner = b.Opponent(winner)
+		}
+
+		s.Line = b.GetKRow(rowOwner)
+		return s
+	}
+
+	if !b.AnyLegalMoves() || b.IsDead() {
+		s.Outcome = Drawn
+	}
+
+	return s
+}
+
+// Resign ends the game with p giving up.
+func (g *Game) Resign(p Player) error {
+	return g.end(Resigned, p)
+}
+
+// TimeOut ends the game with p out of time.
+func (g *Game) TimeOut(p Player) error {
+	return g.end(TimedOut, p)
+}
+
+// Forfeit ends the game with p disqualified or gone.
+func (g *Game) Forfeit(p Player) error {
+	return g.end(Forfeited, p)
+}
+
+// end ends the game with loser leaving it. In a game of two the other player
+// wins, with more players nobody does.
+func (g *Game) end(outcome Outcome, loser Player) error {
+	b := g.Board
+	if !slices.Contains(b.Players(), loser) {
+		return fmt.Errorf("%d is not playing", loser)
+	}
+
+	if g.Status().Over() {
+		return errGameOver
+	}
+
+	s := Status{Outcome: outcome, Loser: loser, Ply: b.Turn}
+	if len(b.Players()) == 2 {
+		s.Winner = b.Opponent(loser)
+	}
+
+	g.ended = &s
+	return nil
+}
@@ -0,0 +1,41 @@
+package tictactoe
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlayAfterGameOver(t *testing.T) {
+	g, err := New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// X takes the top row.
+	for _, idx := range []int{0, 3, 1, 4, 2} {
+		if err := g.PlayMove(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s := g.Status(); s.Outcome != Won || s.Winner != P1 {
+		t.Fatalf("status %+v, expected a win for X", s)
+	}
+
+	if err := g.PlayMove(5); !errors.Is(err, errGameOver) {
+		t.Errorf("move after the win: %v, expected %v", err, errGameOver)
+	}
+
+	if s := g.Status(); s.Outcome != Won || s.Ply != 5 {
+		t.Errorf("status %+v after the refused move, expected the win on ply 5", s)
+	}
+
+	data, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadGame(data); err != nil {
+		t.Errorf("loading the won game: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package tictactoe
+
+import "slices"
+
+// Symmetry is one of the 8 rotations and reflections of a square board.
+type Symmetry int8
+
+const (
+	Identity Symmetry = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	// FlipX mirrors the board left to right.
+	FlipX
+	// FlipY mirrors the board top to bottom.
+	FlipY
+	// Transpose mirrors the board in the diagonal from the top left.
+	Transpose
+	// AntiTranspose mirrors the board in the diagonal from the top right.
+	AntiTranspose
+)
+
+// Inverse returns the symmetry that undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	}
+
+	return s
+}
+
+// Symmetries returns the symmetries that map the board and its rules onto
+// themselves, always starting with Identity. Gravity only allows mirroring
+// left to right, and otherwise only square boards are turned. Unbounded boards
+// have no center to turn around and wrapped boards no edges to line up, so
+// both keep only the identity. Layers of a 3D board are all turned the same
+// way.
+func (b *Board) Symmetries() []Symmetry {
+	var candidates []Symmetry
+	switch {
+	case b.Rules.Unbounded || b.Rules.Wrap:
+		return []Symmetry{Identity}
+	case b.Rules.Gravity:
+		candidates = []Symmetry{Identity, FlipX}
+	case b.W == b.H:
+		candidates = []Symmetry{Identity, Rotate90, Rotate180, Rotate270, FlipX, FlipY, Transpose, AntiTranspose}
+	default:
+		return []Symmetry{Identity}
+	}
+
+	if b.Setup == nil || len(b.Setup.Blocked) == 0 {
+		return candidates
+	}
+
+	// Blocked cells are part of the board, so they have to map onto each other.
+	return slices.DeleteFunc(candidates, func(s Symmetry) bool {
+		for _, m := range b.Setup.Blocked {
+			if b.At(b.MapMove(s, b.moveIdx(m))) != Blocked {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// MapMove returns the cell idx ends up on when the board is turned by s. Use
+// s.Inverse() to map a move back.
+func (b *Board) MapMove(s Symmetry, idx int) int {
+	if s == Identity {
+		return idx
+	}
+
+	x, y := b.coords(idx)
+	w, h := b.W-1, b.H-1
+	switch s {
+	case Rotate90:
+		x, y = h-y, x
+	case Rotate180:
+		x, y = w-x, h-y
+	case Rotate270:
+		x, y = y, w-x
+	case FlipX:
+		x = w - x
+	case FlipY:
+		y = h - y
+	case Transpose:
+		x, y = y, x
+	case AntiTranspose:
+		x, y = h-y, w-x
+	}
+
+	return b.GetIdx3(x, y, b.Layer(idx))
+}
+
+// hashAs returns the hash the position would have after turning it by s.
+func (b *Board) hashAs(s Symmetry) uint64 {
+	var h uint64
+	for i, p := range b.occupied() {
+		if p.IsStone() {
+			h ^= b.key(b.MapMove(s, i), p)
+		}
+	}
+
+	return h
+}
+
+// CanonicalHash returns a hash that is the same for every position that only
+// differs by a symmetry of the board, together with the symmetry that turns
+// the position into its canonical form. The hash is the Hash of the canonical
+// form.
+func (b *Board) CanonicalHash() (uint64, Symmetry) {
+	best, bestSym := b.Hash, Identity
+	for _, s := range b.Symmetries()[1:] {
+		if h := b.hashAs(s); h < best {
+			best, bestSym = h, s
+		}
+	}
+
+	return best, bestSym
+}
+
+// Canonical returns a copy of the board turned into its canonical form and
+// the symmetry that turned it. Moves on the copy map back to the board with
+// MapMove(s.Inverse(), idx).
+func (b *Board) Canonical() (*Board, Symmetry) {
+	_, s := b.CanonicalHash()
+	return b.Transform(s), s
+}
+
+// Transform returns a copy of the board turned by s, with its history and
+// setup turned along with it.
+func (b *Board) Transform(s Symmetry) *Board {
+	t := b.Clone()
+	if s == Identity {
+		return t
+	}
+
+	if b.Setup != nil {
+		setup := *b.Setup
+		setup.Blocked = b.mapCells(s, b.Setup.Blocked)
+		if b.Setup.Stones != nil {
+			setup.Stones = map[Player][]Move{}
+			for p, cells := range b.Setup.Stones {
+				setup.Stones[p] = b.mapCells(s, cells)
+			}
+		}
+
+		if b.Setup.LastMove != nil {
+			setup.LastMove = &b.mapCells(s, []Move{*b.Setup.LastMove})[0]
+		}
+
+		t.Setup = &setup
+	}
+
+	t.reset()
+
+	// The history is replayed so every ply keeps the hash it had.
+	for _, ply := range b.History {
+		if err := t.ApplyMove(b.MapMove(s, ply.Idx), ply.Player); err != nil {
+			panic(err)
+		}
+	}
+
+	return t
+}
+
+func (b *Board) mapCells(s Symmetry, cells []Move) []Move {
+	mapped := make([]Move, len(cells))
+	for i, m := range cells {
+		mapped[i] = b.GetMove(b.MapMove(s, b.moveIdx(m)))
+	}
+
+	return mapped
+}
+
+// UniqueMoves drops moves that lead to the same position as an earlier move
+// up to a symmetry, keeping the first move of each group.
+func (b *Board) UniqueMoves(moves []int) []int {
+	var stabilizers []Symmetry
+	for _, s := range b.Symmetries()[1:] {
+		if b.fixedBy(s) {
+			stabilizers = append(stabilizers, s)
+		}
+	}
+
+	if len(stabilizers) == 0 {
+		return moves
+	}
+
+	seen := make(map[int]bool, len(moves))
+	unique := make([]int, 0, len(moves))
+	for _, idx := range moves {
+		if seen[idx] {
+			continue
+		}
+
+		unique = append(unique, idx)
+		for _, s := range stabilizers {
+			seen[b.MapMove(s, idx)] = true
+		}
+	}
+
+	return unique
+}
+
+// fixedBy reports whether turning the board by s leaves the position as it is.
+func (b *Board) fixedBy(s Symmetry) bool {
+	// The sub-board to play in has to stay where it is.
+	if b.Rules.Ultimate {
+		if active := b.ActiveSubBoard(); active >= 0 {
+			center := b.subCells(active)[subSize*subSize/2]
+			if b.SubBoard(b.MapMove(s, center)) != active {
+				return false
+			}
+		}
+	}
+
+	for i, p := range b.Cells {
+		if b.Cells[b.MapMove(s, i)] != p {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,151 @@
+package tictactoe
+
+import (
+	"slices"
+	"testing"
+)
+
+var allSymmetries = []Symmetry{Identity, Rotate90, Rotate180, Rotate270, FlipX, FlipY, Transpose, AntiTranspose}
+
+func TestSymmetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		opts    []Option
+		want    []Symmetry
+	}{
+		{"square", 5, 5, 4, nil, allSymmetries},
+		{"cube", 3, 3, 3, []Option{WithDepth(3)}, allSymmetries},
+		{"rectangular", 4, 3, 3, nil, []Symmetry{Identity}},
+		{"wrapped", 5, 5, 4, []Option{WithWrap()}, []Symmetry{Identity}},
+		{"unbounded", 15, 15, 5, []Option{WithUnbounded()}, []Symmetry{Identity}},
+		{"gravity", 7, 6, 4, []Option{WithGravity()}, []Symmetry{Identity, FlipX}},
+		{"blocked corner", 5, 5, 4, []Option{WithBlocked(Move{X: 0, Y: 0})}, []Symmetry{Identity, Transpose}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := g.Board.Symmetries(); !slices.Equal(got, tt.want) {
+				t.Errorf("symmetries %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapMoveInverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h, k int
+		opts    []Option
+	}{
+		{"3x3", 3, 3, 3, nil},
+		{"6x6", 6, 6, 4, nil},
+		{"cube", 4, 4, 4, []Option{WithDepth(4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, tt.k, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			for _, s := range allSymmetries {
+				seen := map[int]bool{}
+				for idx := range b.Cells {
+					mapped := b.MapMove(s, idx)
+					if back := b.MapMove(s.Inverse(), mapped); back != idx {
+						t.Errorf("%v maps %s to %s, and its inverse back to %s", s, b.FormatMove(idx), b.FormatMove(mapped), b.FormatMove(back))
+					}
+
+					if b.Layer(mapped) != b.Layer(idx) {
+						t.Errorf("%v moves %s to another layer", s, b.FormatMove(idx))
+					}
+
+					seen[mapped] = true
+				}
+
+				if len(seen) != len(b.Cells) {
+					t.Errorf("%v maps %d cells onto %d", s, len(b.Cells), len(seen))
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	g, err := New(5, 5, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No symmetry maps these stones onto themselves.
+	b := g.Board
+	for _, m := range []Move{{X: 0, Y: 0}, {X: 3, Y: 2}, {X: 1, Y: 0}} {
+		if err := g.PlayMove(b.moveIdx(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, _ := b.CanonicalHash()
+	hashes := map[uint64]bool{}
+	for _, s := range allSymmetries {
+		turned := b.Transform(s)
+		hashes[turned.Hash] = true
+
+		h, sym := turned.CanonicalHash()
+		if h != want {
+			t.Errorf("turned by %v the canonical hash is %#x, expected %#x", s, h, want)
+		}
+
+		if canonical := turned.Transform(sym); canonical.Hash != want {
+			t.Errorf("turned by %v and then %v the hash is %#x, expected the canonical %#x", s, sym, canonical.Hash, want)
+		}
+	}
+
+	if len(hashes) != len(allSymmetries) {
+		t.Errorf("%d different positions, expected %d", len(hashes), len(allSymmetries))
+	}
+}
+
+func TestUniqueMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		w, h  int
+		opts  []Option
+		moves []Move
+		want  int
+	}{
+		{"empty 3x3", 3, 3, nil, nil, 3},
+		{"center taken", 3, 3, nil, []Move{{X: 1, Y: 1}}, 2},
+		{"corner taken", 3, 3, nil, []Move{{X: 0, Y: 0}}, 5},
+		{"rectangular", 4, 3, nil, nil, 12},
+		{"wrapped", 3, 3, []Option{WithWrap()}, nil, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.w, tt.h, 3, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := g.Board
+			for _, m := range tt.moves {
+				if err := g.PlayMove(b.moveIdx(m)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := b.UniqueMoves(b.LegalMoves()); len(got) != tt.want {
+				t.Errorf("unique moves %s, expected %d of them", b.FormatMoves(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package tictactoe
+
+import (
+	"maps"
+	"slices"
+)
+
+// Threat is a window of K cells on a line that holds only stones of one
+// player and is one or two stones short of a row.
+type Threat struct {
+	Player Player
+	Stones []int
+	// Gaps are the empty cells of the window that complete the row.
+	Gaps []int
+	// Open says whether the cell just before and just after the window is
+	// empty, so the row could also be extended that way.
+	Open [2]bool
+}
+
+// OpenEnds returns how many ends of the threat are open.
+func (t Threat) OpenEnds() int {
+	n := 0
+	for _, open := range t.Open {
+		if open {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Threats lists every window of K cells in which p has K-1 or K-2 stones and
+// the rest is empty. Overlapping windows on the same line are listed
+// separately, so an open four shows up once for each cell that completes it.
+func (b *Board) Threats(p Player) []Threat {
+	return b.threats(p, 2)
+}
+
+// threats lists the windows in which p is at most short stones short of a row
+// and the rest is empty.
+func (b *Board) threats(p Player, short int) []Threat {
+	type window struct {
+		start int
+		dir   int
+	}
+
+	seen := map[window]bool{}
+	var threats []Threat
+	for idx, c := range b.occupied() {
+		if c != p {
+			continue
+		}
+
+		for di, d := range b.lines() {
+			for j := range b.K {
+				start, ok := b.step(idx, d, -j)
+				if !ok || seen[window{start, di}] {
+					continue
+				}
+
+				seen[window{start, di}] = true
+				if t, ok := b.threatAt(start, d, p); ok && len(t.Gaps) <= short {
+					threats = append(threats, t)
+				}
+			}
+		}
+	}
+
+	return threats
+}
+
+// threatAt reads the window of K cells from start in direction d. It fails if
+// the window runs off the board or holds anything but empty cells and stones
+// of p.
+func (b *Board) threatAt(start int, d Dir, p Player) (Threat, bool) {
+	if b.lineLength(d) < b.K {
+		return Threat{}, false
+	}
+
+	t := Threat{Player: p}
+	for i := range b.K {
+		idx, ok := b.step(start, d, i)
+		if !ok {
+			return Threat{}, false
+		}
+
+		switch b.At(idx) {
+		case p:
+			t.Stones = append(t.Stones, idx)
+		case Empty:
+			t.Gaps = append(t.Gaps, idx)
+		default:
+			return Threat{}, false
+		}
+	}
+
+	// On a wrapped line exactly K long the ends are part of the window.
+	if b.lineLength(d) > b.K {
+		for i, n := range []int{-1, b.K} {
+			end, ok := b.step(start, d, n)
+			t.Open[i] = ok && b.At(end) == Empty
+		}
+	}
+
+	return t, true
+}
+
+// WinningCells returns the empty cells that would give p a row of K. Under
+// gravity some of them may not be playable yet.
+func (b *Board) WinningCells(p Player) []int {
+	var cells []int
+	for _, t := range b.Threats(p) {
+		if len(t.Gaps) == 1 && !slices.Contains(cells, t.Gaps[0]) {
+			cells = append(cells, t.Gaps[0])
+		}
+	}
+
+	return cells
+}
+
+// ForkMoves returns the legal moves that give p two threats at once: two
+// different cells to win on, or threats along two different lines where each
+// is either one stone short or two stones short with both ends open. Lone
+// stones do not count as threats, so forks on boards with K=3 need two cells
+// to win on.
+func (b *Board) ForkMoves(p Player) []int {
+	var forks []int
+	for _, idx := range b.LegalMoves() {
+		if b.IsFork(idx, p) {
+			forks = append(forks, idx)
+		}
+	}
+
+	return forks
+}
+
+// IsFork reports whether p playing idx makes a double threat. Only threats
+// that go through idx count.
+func (b *Board) IsFork(idx int, p Player) bool {
+	if b.At(idx) != Empty {
+		return false
+	}
+
+	b.put(idx, p)
+	defer b.put(idx, Empty)
+
+	var wins []int
+	lines := 0
+	for _, d := range b.lines() {
+		threat := false
+		for j := range b.K {
+			start, ok := b.step(idx, d, -j)
+			if !ok {
+				continue
+			}
+
+			t, ok := b.threatAt(start, d, p)
+			if !ok {
+				continue
+			}
+
+			switch {
+			case len(t.Gaps) == 0:
+				// A move that wins outright is not a fork.
+				return false
+			case len(t.Gaps) == 1:
+				threat = true
+				if !slices.Contains(wins, t.Gaps[0]) {
+					wins = append(wins, t.Gaps[0])
+				}
+			case len(t.Gaps) == 2 && len(t.Stones) >= 2 && t.OpenEnds() == 2:
+				threat = true
+			}
+		}
+
+		if threat {
+			lines++
+		}
+	}
+
+	return len(wins) >= 2 || lines >= 2
+}
+
+// multiStoneTacticalMoves is TacticalMoves for turns of more than one stone.
+// A win is a window the stones left this turn complete, and the windows an
+// opponent completes with the stones of their next turn have to be blocked.
+// Of their gaps, the ones that block the most windows are returned.
+func (b *Board) multiStoneTacticalMoves(player Player) ([]int, bool) {
+	for _, t := range b.threats(player, b.StonesLeft()) {
+		if b.completes(t.Gaps, player) {
+			return t.Gaps[:1], true
+		}
+	}
+
+	blocked := map[int]int{}
+	for _, opponent := range b.Players() {
+		if opponent == player {
+			continue
+		}
+
+		for _, t := range b.threats(opponent, b.nextTurnStones()) {
+			for _, idx := range t.Gaps {
+				if b.IsLegal(idx) {
+					blocked[idx]++
+				}
+			}
+		}
+	}
+
+	most := 0
+	var blocks []int
+	for _, idx := range slices.Sorted(maps.Keys(blocked)) {
+		switch n := blocked[idx]; {
+		case n > most:
+			most, blocks = n, []int{idx}
+		case n == most:
+			blocks = append(blocks, idx)
+		}
+	}
+
+	return blocks, false
+}
+
+// completes reports whether p placing stones on cells, in order, wins.
+func (b *Board) completes(cells []int, p Player) bool {
+	placed := 0
+	defer func() {
+		for i := placed - 1; i >= 0; i-- {
+			b.UndoMove(cells[i])
+		}
+	}()
+
+	for _, idx := range cells {
+		if b.ApplyMove(idx, p) != nil {
+			return false
+		}
+
+		placed++
+	}
+
+	return b.CheckWinner() == p
+}
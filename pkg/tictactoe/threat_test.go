@@ -0,0 +1,174 @@
+package tictactoe
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// shapeBoard puts a shape drawn in rows of X, O and "." on the top left of an
+// empty 7x7 board with rows of 4, and returns the cell marked "*".
+func shapeBoard(t *testing.T, shape []string) (*Board, int) {
+	t.Helper()
+
+	g, err := New(7, 7, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := g.Board
+	target := -1
+	for y, row := range shape {
+		for x, c := range row {
+			idx := b.GetIdx(x, y)
+			switch c {
+			case 'X':
+				err = b.ApplyMove(idx, P1)
+			case 'O':
+				err = b.ApplyMove(idx, P2)
+			case '*':
+				target = idx
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return b, target
+}
+
+func TestWinningCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape []string
+		want  []Move
+	}{
+		{"open three", []string{".XXX..."}, []Move{{X: 0}, {X: 4}}},
+		{"blocked three", []string{"OXXX..."}, []Move{{X: 4}}},
+		{"split three", []string{"X.XX..."}, []Move{{X: 1}}},
+		{"two", []string{".XX...."}, nil},
+		{"diagonal", []string{
+			"X......",
+			".X.....",
+			"..X....",
+		}, []Move{{X: 3, Y: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := shapeBoard(t, tt.shape)
+
+			var want []int
+			for _, m := range tt.want {
+				want = append(want, b.GetIdx(m.X, m.Y))
+			}
+
+			got := b.WinningCells(P1)
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("winning cells of\n%s\n= %v, expected %v", strings.Join(tt.shape, "\n"), got, want)
+			}
+		})
+	}
+}
+
+func TestIsFork(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape []string
+		fork  bool
+	}{
+		{"open three", []string{".XX*..."}, true},
+		{"closed three", []string{"OXX*..."}, false},
+		{"win", []string{"XXX*..."}, false},
+		{"two lines", []string{
+			"...X...",
+			"...X...",
+			".XX*...",
+		}, true},
+		{"lone stones", []string{
+			"...X...",
+			"...*X..",
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, target := shapeBoard(t, tt.shape)
+			if got := b.IsFork(target, P1); got != tt.fork {
+				t.Errorf("IsFork on\n%s\n= %t, expected %t", strings.Join(tt.shape, "\n"), got, tt.fork)
+			}
+
+			if got := slices.Contains(b.ForkMoves(P1), target); got != tt.fork {
+				t.Errorf("ForkMoves contains the move: %t, expected %t", got, tt.fork)
+			}
+		})
+	}
+}
+
+// connect6Board puts a shape drawn in rows of X, O and "." on the top left of
+// a 12x12 board where rows of 6 are made with two stones a turn, turn plies
+// after the first stone.
+func connect6Board(t *testing.T, shape []string, turn int) *Board {
+	t.Helper()
+
+	setup := Setup{Stones: map[Player][]Move{}, Turn: turn}
+	for y, row := range shape {
+		for x, c := range row {
+			switch c {
+			case 'X':
+				setup.Stones[P1] = append(setup.Stones[P1], Move{X: x, Y: y})
+			case 'O':
+				setup.Stones[P2] = append(setup.Stones[P2], Move{X: x, Y: y})
+			}
+		}
+	}
+
+	setup.LastMove = &setup.Stones[P1][0]
+	g, err := New(12, 12, 6, WithStonesPerTurn(2, 1), WithSetup(setup))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g.Board
+}
+
+func TestTacticalMovesConnect6(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape []string
+		turn  int
+		moves []Move
+		win   bool
+	}{
+		// O has both stones of the turn to play.
+		{"four in the middle", []string{"...XXXX.....", "O..........."}, 1, []Move{{X: 2}, {X: 7}}, false},
+		{"four on the edge", []string{"XXXX........", "O..........."}, 1, []Move{{X: 4}, {X: 5}}, false},
+		{"split four", []string{"XX.XX.......", "O..........."}, 1, []Move{{X: 2}, {X: 5}}, false},
+		{"three", []string{"XXX.........", "O..........."}, 1, nil, false},
+		{"own four first", []string{"XXXX........", "OOOO........"}, 1, []Move{{X: 4, Y: 1}}, true},
+		// O has one stone left after blocking on h12.
+		{"second stone", []string{"...XXXXO....", "O..........."}, 2, []Move{{X: 1}, {X: 2}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := connect6Board(t, tt.shape, tt.turn)
+			if b.ToMove() != P2 {
+				t.Fatalf("%s to move, expected O", b.ToMove().Mark())
+			}
+
+			var want []int
+			for _, m := range tt.moves {
+				want = append(want, b.moveIdx(m))
+			}
+
+			moves, win := b.TacticalMoves(P2)
+			if !slices.Equal(moves, want) || win != tt.win {
+				t.Errorf("TacticalMoves = %s, %t, expected %s, %t", b.FormatMoves(moves), win, b.FormatMoves(want), tt.win)
+			}
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package tictactoe
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"math/rand/v2"
 	"slices"
@@ -19,28 +19,45 @@ const (
 	Empty Player = 0
 	P1    Player = 1
 	P2    Player = -1
+	P3    Player = 2
+	P4    Player = 3
+
+	// Blocked is a cell nobody can play on.
+	Blocked Player = -2
 )
 
-func (p Player) Mark() string {
-	s := " "
-	if p == P1 {
-		s = "X"
-	}
+// turnOrder lists every player in the order they take turns.
+var turnOrder = []Player{P1, P2, P3, P4}
 
-	if p == P2 {
-		s = "O"
-	}
+const MaxPlayers = 4
 
-	return s
+func (p Player) Mark() string {
+	switch p {
+	case P1:
+		return "X"
+	case P2:
+		return "O"
+	case P3:
+		return "Y"
+	case P4:
+		return "Z"
+	case Blocked:
+		return "#"
+	}
+
+	return " "
 }
 
 func (p Player) Idx() int {
-	if p == P1 {
+	switch p {
+	case P1:
 		return 0
-	}
-
-	if p == P2 {
+	case P2:
 		return 1
+	case P3:
+		return 2
+	case P4:
+		return 3
 	}
 
 	return -1
@@ -49,91 +66,248 @@ func (p Player) Idx() int {
 type Move struct {
 	X int
 	Y int
+	Z int `json:",omitempty"`
 }
 
 type Board struct {
-	N            int
-	K            int
-	Cells        []Player
-	LastMove     int
-	LastMoveUndo int
-	Hash         uint64
-	Turn         int
-
-	xList      []int
-	yList      []int
-	emptyCells []int
+	W        int
+	H        int
+	D        int `json:",omitempty"`
+	K        int
+	Rules    Rules
+	Cells    []Player
+	Stones   map[int]Player `json:",omitempty"`
+	LastMove int
+	Hash     uint64
+	Turn     int
+	History  []Ply  `json:",omitempty"`
+	Setup    *Setup `json:",omitempty"`
+
+	xList  []int
+	yList  []int
+	zList  []int
+	near   map[int]int
+	bits   *bitboards
+	counts *lineCounts
+
+	// captures counts the pairs each player has captured.
+	captures [MaxPlayers]int
 
 	game *Game
 }
 
 func (b *Board) GetIdx(x, y int) int {
-	return y*b.N + x
+	if b.Rules.Unbounded {
+		return (y+unboundedSpan)*unboundedStride + x + unboundedSpan
+	}
+
+	return y*b.W + x
 }
 
 func (b *Board) GetMove(idx int) Move {
+	x, y := b.coords(idx)
 	return Move{
-		X: idx % b.N,
-		Y: idx / b.N,
+		X: x,
+		Y: y,
+		Z: b.Layer(idx),
 	}
 }
 
+func (b *Board) InBounds(x, y int) bool {
+	if b.Rules.Unbounded {
+		return x >= -unboundedSpan && y >= -unboundedSpan && x < unboundedSpan && y < unboundedSpan
+	}
+
+	return x >= 0 && y >= 0 && x < b.W && y < b.H
+}
+
 func (b *Board) Get(x, y int) Player {
 	idx := b.GetIdx(x, y)
-	return b.Cells[idx]
+	return b.At(idx)
 }
 
 func (b *Board) Play(p Player, m Move) {
-	idx := b.GetIdx(m.X, m.Y)
+	idx := b.moveIdx(m)
 	b.ApplyMove(idx, p)
 }
 
 func (b *Board) ApplyMove(idx int, p Player) error {
-	if b.Cells[idx] != Empty {
-		return fmt.Errorf("%d is at %d: %w", b.Cells[idx], idx, errIllegalMove)
+	if !b.validIdx(idx) {
+		return fmt.Errorf("%d is outside the board: %w", idx, errIllegalMove)
 	}
 
-	b.Cells[idx] = p
+	if c := b.At(idx); c != Empty {
+		return fmt.Errorf("%d is at %d: %w", c, idx, errIllegalMove)
+	}
 
-	b.LastMoveUndo = b.LastMove
+	if !b.isLegalFor(idx, p) {
+		return fmt.Errorf("%d can not be played: %w", idx, errIllegalMove)
+	}
+
+	b.place(idx, p)
+	b.Turn++
 	b.LastMove = idx
-	b.Hash ^= b.game.ZobristKeys[idx][p.Idx()]
 
-	b.emptyCells = slices.DeleteFunc(b.emptyCells, func(cmp int) bool {
-		return cmp == idx
-	})
+	ply := Ply{Player: p, Idx: idx}
+	if b.Rules.Capture > 0 {
+		ply.Captured = b.capture(idx, p)
+	}
+
+	ply.Hash = b.Hash
+	b.History = append(b.History, ply)
 
 	return nil
 }
 
+// UndoMove takes back idx, which has to be the last ply played.
 func (b *Board) UndoMove(idx int) error {
-	if b.Cells[idx] == Empty {
-		return errIllegalMove
+	last, ok := b.LastPly()
+	if !ok || last.Idx != idx {
+		return fmt.Errorf("%d is not the last move: %w", idx, errIllegalMove)
 	}
 
-	b.LastMove = b.LastMoveUndo
+	b.History = b.History[:len(b.History)-1]
+	b.LastMove = b.setupLastMove()
+	if prev, ok := b.LastPly(); ok {
+		b.LastMove = prev.Idx
+	}
 
-	p := b.Cells[idx]
-	b.Cells[idx] = Empty
+	for _, s := range last.Captured {
+		b.place(s.Idx, s.Player)
+	}
 
-	b.emptyCells = append(b.emptyCells, idx)
+	if len(last.Captured) > 0 {
+		b.captures[last.Player.Idx()] -= len(last.Captured) / 2
+	}
 
-	b.Hash ^= b.game.ZobristKeys[idx][p.Idx()]
+	b.lift(idx, b.At(idx))
+	b.Turn--
 
 	return nil
 }
 
+// place puts p on idx and keeps the hash and the derived state in step.
+func (b *Board) place(idx int, p Player) {
+	b.put(idx, p)
+	b.Hash ^= b.key(idx, p)
+
+	if b.Rules.Unbounded {
+		b.updateNear(idx, 1)
+	}
+
+	if b.bits != nil {
+		b.bits.place(idx, b.W, p)
+	}
+
+	if b.counts != nil {
+		b.counts.add(idx, p)
+	}
+}
+
+// lift removes p from idx, undoing place.
+func (b *Board) lift(idx int, p Player) {
+	b.put(idx, Empty)
+	b.Hash ^= b.key(idx, p)
+
+	if b.Rules.Unbounded {
+		b.updateNear(idx, -1)
+	}
+
+	if b.bits != nil {
+		b.bits.remove(idx, b.W, p)
+	}
+
+	if b.counts != nil {
+		b.counts.remove(idx, p)
+	}
+}
+
+// key returns the zobrist key of p standing on idx.
+func (b *Board) key(idx int, p Player) uint64 {
+	if b.Rules.Unbounded {
+		return zobrist.Key(b.game.ZobristSeed, idx, p.Idx())
+	}
+
+	return b.game.ZobristKeys[idx][p.Idx()]
+}
+
+// ToMove returns the player whose turn it is.
+func (b *Board) ToMove() Player {
+	turn, _ := b.turnAt(b.Turn)
+	players := b.Players()
+	return players[turn%len(players)]
+}
+
+// Players returns the players in the game in turn order.
+func (b *Board) Players() []Player {
+	return turnOrder[:max(2, b.Rules.Players)]
+}
+
 func (b *Board) AnyLegalMoves() bool {
+	if b.Rules.Unbounded {
+		return true
+	}
+
+	if legal, ok := b.legalBits(); ok {
+		return !legal.empty()
+	}
+
+	if b.Rules.Set == Renju && b.ToMove() == P1 {
+		return len(b.LegalMoves()) > 0
+	}
+
 	return slices.Contains(b.Cells, Empty)
 }
 
+// LegalMoves returns the moves the player to move can make. On unbounded boards
+// only the cells near existing stones are returned.
 func (b *Board) LegalMoves() []int {
-	emptyCells := make([]int, 0, (b.N * b.N))
+	if b.Rules.Unbounded {
+		moves := b.candidates()
+		if b.Rules.Set == Renju && b.ToMove() == P1 {
+			moves = slices.DeleteFunc(moves, b.Forbidden)
+		}
+
+		return moves
+	}
+
+	if b.Rules.Gravity {
+		moves := make([]int, 0, b.W)
+		for x := range b.W {
+			if idx, ok := b.Drop(x); ok && b.IsLegal(idx) {
+				moves = append(moves, idx)
+			}
+		}
+
+		return moves
+	}
+
+	if legal, ok := b.legalBits(); ok {
+		moves := make([]int, 0, legal.count())
+		b.ForEachLegalMove(func(idx int) bool {
+			moves = append(moves, idx)
+			return true
+		})
+
+		return moves
+	}
+
+	restricted := b.Rules.Set == Renju && b.ToMove() == P1
+
+	emptyCells := make([]int, 0, len(b.Cells))
 	for m, p := range b.Cells {
 		if p != Empty {
 			continue
 		}
 
+		if restricted && b.Forbidden(m) {
+			continue
+		}
+
+		if b.Rules.Ultimate && !b.ultimateLegal(m) {
+			continue
+		}
+
 		emptyCells = append(emptyCells, m)
 	}
 
@@ -141,7 +315,20 @@ func (b *Board) LegalMoves() []int {
 }
 
 func (b *Board) CheckWinner() Player {
+	if winner := b.captureWinner(); winner != Empty {
+		return winner
+	}
+
+	if b.Rules.Ultimate {
+		winner, _ := b.metaLine()
+		return winner
+	}
+
 	winner := b.checkFrom(b.LastMove)
+	if b.Rules.Misere && winner != Empty {
+		return b.Opponent(winner)
+	}
+
 	return winner
 }
 
@@ -149,198 +336,278 @@ func (b *Board) Clone() *Board {
 	cells := make([]Player, len(b.Cells))
 	copy(cells, b.Cells)
 
-	emptyCells := make([]int, len(b.emptyCells))
-	copy(emptyCells, b.emptyCells)
+	// Clones are mostly played out to the end, so leave room for the rest of
+	// the game up front.
+	history := make([]Ply, len(b.History), max(len(b.History), len(b.Cells)))
+	copy(history, b.History)
+
+	var bits *bitboards
+	if b.bits != nil {
+		clone := *b.bits
+		bits = &clone
+	}
+
+	var counts *lineCounts
+	if b.counts != nil {
+		counts = b.counts.clone()
+	}
 
 	return &Board{
-		N:          b.N,
-		K:          b.K,
-		Cells:      cells,
-		xList:      b.xList,
-		yList:      b.yList,
-		emptyCells: emptyCells,
-		Hash:       b.Hash,
-		LastMove:   b.LastMove,
-		Turn:       b.Turn,
-		game:       b.game,
+		W:        b.W,
+		H:        b.H,
+		D:        b.D,
+		K:        b.K,
+		Rules:    b.Rules,
+		Cells:    cells,
+		Stones:   maps.Clone(b.Stones),
+		near:     maps.Clone(b.near),
+		xList:    b.xList,
+		yList:    b.yList,
+		zList:    b.zList,
+		bits:     bits,
+		counts:   counts,
+		Hash:     b.Hash,
+		LastMove: b.LastMove,
+		Turn:     b.Turn,
+		History:  history,
+		Setup:    b.Setup,
+		captures: b.captures,
+		game:     b.game,
 	}
 }
 
 type Dir struct {
-	dx, dy int
+	dx, dy, dz int
 }
 
 var directions = []Dir{
-	{1, 0},  // horizontal
-	{0, 1},  // vertical
-	{1, 1},  // diag
-	{1, -1}, // anti-diag
+	{1, 0, 0},  // horizontal
+	{0, 1, 0},  // vertical
+	{1, 1, 0},  // diag
+	{1, -1, 0}, // anti-diag
 }
 
 func (b *Board) checkFrom(idx int) Player {
-	p := b.Cells[idx]
-	if p == Empty {
+	if !b.validIdx(idx) {
 		return Empty
 	}
 
-	N := b.N
-	x := b.xList[idx]
-	y := b.yList[idx]
-
-	for _, d := range directions {
-		count := 1
+	p := b.At(idx)
+	if !p.IsStone() {
+		return Empty
+	}
 
-		for _, dir := range []int{-1, 1} {
-			nx := x + d.dx*dir
-			ny := y + d.dy*dir
+	// Any row of K or more fills a window, so without a full window there is
+	// no row, and under freestyle rules a full window is all it takes.
+	if b.counts != nil {
+		if b.counts.full[p.Idx()] == 0 {
+			return Empty
+		}
 
-			for nx >= 0 && ny >= 0 && nx < N && ny < N {
-				nidx := ny*N + nx
-				if b.Cells[nidx] != p {
-					break
-				}
+		if b.Rules.Set == Freestyle {
+			if _, ok := b.counts.fullLineThrough(idx, p); ok {
+				return p
+			}
 
-				count++
-				if count >= b.K {
-					return p
-				}
+			return Empty
+		}
+	}
 
-				nx += d.dx * dir
-				ny += d.dy * dir
-			}
+	for _, d := range b.lines() {
+		back, fwd := b.run(idx, d, p)
+		if b.wins(back+fwd+1, p) {
+			return p
 		}
 	}
+
 	return Empty
 }
 
-func (b *Board) GetKRow(winner Player) []int {
-	if winner == Empty {
-		return nil
+// step returns the cell n steps away from idx along d.
+func (b *Board) step(idx int, d Dir, n int) (int, bool) {
+	x, y := b.coords(idx)
+	z := b.Layer(idx)
+	x += d.dx * n
+	y += d.dy * n
+	z += d.dz * n
+	if b.Rules.Wrap {
+		x = mod(x, b.W)
+		y = mod(y, b.H)
+		z = mod(z, b.depth())
 	}
 
-	N := b.N
+	if !b.InBounds(x, y) || z < 0 || z >= b.depth() {
+		return -1, false
+	}
 
-	for move, stone := range b.Cells {
-		if stone != winner {
-			continue
+	// Lines on an ultimate board stay within their sub-board.
+	if b.Rules.Ultimate {
+		x0, y0 := b.coords(idx)
+		if x/subSize != x0/subSize || y/subSize != y0/subSize {
+			return -1, false
 		}
+	}
 
-		x := b.xList[move]
-		y := b.yList[move]
-
-		for _, d := range directions {
-			count := 1
-			kMove := []int{move}
+	return b.GetIdx3(x, y, z), true
+}
 
-			for _, dir := range []int{-1, 1} {
-				nx := x + d.dx*dir
-				ny := y + d.dy*dir
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
 
-				for nx >= 0 && ny >= 0 && nx < N && ny < N {
-					nidx := ny*N + nx
-					if b.Cells[nidx] != winner {
-						break
-					}
+// lineLength returns how many cells a line along d can hold at most. Lines on
+// a wrapped board loop around, so a run can not be longer than the loop.
+func (b *Board) lineLength(d Dir) int {
+	switch {
+	case b.Rules.Unbounded:
+		return unboundedStride
+	case b.Rules.Ultimate:
+		return subSize
+	}
+
+	length := 0
+	for _, axis := range [...][2]int{{d.dx, b.W}, {d.dy, b.H}, {d.dz, b.depth()}} {
+		switch {
+		case axis[0] == 0:
+		case length == 0:
+			length = axis[1]
+		case b.Rules.Wrap:
+			length = lcm(length, axis[1])
+		default:
+			length = min(length, axis[1])
+		}
+	}
 
-					count++
-					kMove = append(kMove, nidx)
-					if count >= b.K {
-						return kMove
-					}
+	return length
+}
 
-					nx += d.dx * dir
-					ny += d.dy * dir
-				}
-			}
-		}
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
 	}
 
-	return nil
+	return a / x * b
 }
 
-func (b *Board) TacticalStone(idx int) bool {
-	x := idx % b.N
-	y := idx / b.N
-
-	t := false
-	for _, d := range directions {
-		if b.checkOneColorFromSide(x, y, d.dx, d.dy) || b.checkOneColorFromSide(x, y, -d.dx, -d.dy) {
-			t = true
-			break
+// run counts the stones of p directly behind and in front of idx along d.
+func (b *Board) run(idx int, d Dir, p Player) (int, int) {
+	limit := b.lineLength(d) - 1
+
+	counts := [2]int{}
+	for i, dir := range []int{1, -1} {
+		for counts[0]+counts[1] < limit {
+			nidx, ok := b.step(idx, d, dir*(counts[i]+1))
+			if !ok || b.At(nidx) != p {
+				break
+			}
+
+			counts[i]++
 		}
 	}
 
-	return t
+	return counts[1], counts[0]
 }
 
-func (b *Board) checkOneColorFromSide(x, y, dx, dy int) bool {
-	nx := x + dx
-	ny := y + dy
-
-	if nx < 0 || ny < 0 || nx >= b.N || ny >= b.N {
-		return false
+func (b *Board) GetKRow(winner Player) []int {
+	if !winner.IsStone() {
+		return nil
 	}
 
-	color := b.Cells[ny*b.N+nx]
-	if color == Empty {
-		return false
+	if b.Rules.Ultimate {
+		return b.ultimateKRow(winner)
 	}
 
-	count := 1
+	if b.counts != nil {
+		return b.counts.row(b, winner)
+	}
 
-	fx, fy := nx, ny
-	for {
-		count++
-		fx += dx
-		fy += dy
-		if fx < 0 || fy < 0 || fx >= b.N || fy >= b.N {
-			break
+	for move, stone := range b.occupied() {
+		if stone != winner {
+			continue
 		}
-		if b.Cells[fy*b.N+fx] != color {
-			break
+
+		if row := b.rowFrom(move, winner); row != nil {
+			return row
 		}
 	}
 
-	bx := x - dx
-	by := y - dy
-	for {
-		if bx < 0 || by < 0 || bx >= b.N || by >= b.N {
-			break
+	return nil
+}
+
+// rowFrom returns a winning row of p that starts on move.
+func (b *Board) rowFrom(move int, p Player) []int {
+	for _, d := range b.lines() {
+		back, fwd := b.run(move, d, p)
+		if back > 0 || !b.wins(fwd+1, p) {
+			continue
 		}
-		if b.Cells[by*b.N+bx] != color {
-			break
+
+		kMove := []int{move}
+		for i := 1; i <= fwd; i++ {
+			nidx, _ := b.step(move, d, i)
+			kMove = append(kMove, nidx)
 		}
-		count++
-		bx -= dx
-		by -= dy
+
+		return kMove
+	}
+
+	return nil
+}
+
+func (b *Board) TacticalStone(idx int) bool {
+	for _, d := range b.lines() {
+		if b.checkOneColorFromSide(idx, d, 1) || b.checkOneColorFromSide(idx, d, -1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Board) checkOneColorFromSide(idx int, d Dir, dir int) bool {
+	nidx, ok := b.step(idx, d, dir)
+	if !ok {
+		return false
+	}
+
+	color := b.At(nidx)
+	if !color.IsStone() {
+		return false
 	}
 
-	return count >= b.K
+	back, fwd := b.run(idx, d, color)
+	return back+fwd+1 >= b.K
 }
 
 func (b *Board) HasNeighbor(idx int, r int) bool {
-	x := b.xList[idx]
-	y := b.yList[idx]
+	x, y := b.coords(idx)
+	z := b.Layer(idx)
 
-	for dy := -r; dy <= r; dy++ {
-		ny := y + dy
-		if ny < 0 || ny >= b.N {
-			continue
-		}
+	rz := 0
+	if b.D > 1 {
+		rz = r
+	}
 
-		for dx := -r; dx <= r; dx++ {
-			nx := x + dx
-			if nx < 0 || nx >= b.N {
-				continue
-			}
+	for dz := -rz; dz <= rz; dz++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if dx == 0 && dy == 0 && dz == 0 {
+					continue
+				}
 
-			if dx == 0 && dy == 0 {
-				continue
-			}
+				nx, ny, nz := x+dx, y+dy, z+dz
+				if b.Rules.Wrap {
+					nx, ny, nz = mod(nx, b.W), mod(ny, b.H), mod(nz, b.depth())
+				}
+
+				if !b.InBounds3(nx, ny, nz) {
+					continue
+				}
 
-			nidx := ny*b.N + nx
-			if b.Cells[nidx] != Empty {
-				return true
+				if b.At(b.GetIdx3(nx, ny, nz)).IsStone() {
+					return true
+				}
 			}
 		}
 	}
@@ -348,26 +615,41 @@ func (b *Board) HasNeighbor(idx int, r int) bool {
 	return false
 }
 
+// TacticalMoves returns a move that wins this turn, along with true, or else
+// the moves that block a row an opponent could complete on their next turn.
 func (b *Board) TacticalMoves(player Player) ([]int, bool) {
+	if b.Rules.Misere {
+		return b.misereTacticalMoves(player)
+	}
+
+	if b.StonesLeft() > 1 || b.nextTurnStones() > 1 {
+		return b.multiStoneTacticalMoves(player)
+	}
+
 	blockingMoves := []int{}
 
-	for i, c := range b.Cells {
-		if c != Empty {
+	for _, i := range b.LegalMoves() {
+		if b.ApplyMove(i, player) != nil {
 			continue
 		}
 
-		b.ApplyMove(i, player)
 		isWin := b.CheckWinner() == player
 		b.UndoMove(i)
 		if isWin {
 			return []int{i}, true
 		}
 
-		b.ApplyMove(i, -player)
-		isBlock := b.CheckWinner() != Empty
-		b.UndoMove(i)
-		if isBlock {
-			blockingMoves = append(blockingMoves, i)
+		for _, opponent := range b.Players() {
+			if opponent == player || b.ApplyMove(i, opponent) != nil {
+				continue
+			}
+
+			isBlock := b.CheckWinner() != Empty
+			b.UndoMove(i)
+			if isBlock {
+				blockingMoves = append(blockingMoves, i)
+				break
+			}
 		}
 	}
 
@@ -383,11 +665,7 @@ func (b *Board) BiasedRandomMove() int {
 	var empty []int
 
 	r := 2
-	for m, p := range b.Cells {
-		if p != Empty {
-			continue
-		}
-
+	for _, m := range b.LegalMoves() {
 		empty = append(empty, m)
 
 		if r > 0 && b.HasNeighbor(m, r) {
@@ -404,10 +682,21 @@ func (b *Board) BiasedRandomMove() int {
 }
 
 func (b *Board) Print() {
-	fmt.Printf("%#v\n", b.Cells)
-	for i, p := range b.Cells {
-		fmt.Printf("[%s]", p.Mark())
-		if (i+1)%b.N == 0 {
+	if !b.Rules.Unbounded {
+		fmt.Printf("%#v\n", b.Cells)
+	}
+
+	minX, minY, maxX, maxY := b.Bounds()
+	for z := range b.depth() {
+		if z > 0 {
+			fmt.Print("\n")
+		}
+
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				fmt.Printf("[%s]", b.At(b.GetIdx3(x, y, z)).Mark())
+			}
+
 			fmt.Print("\n")
 		}
 	}
@@ -417,12 +706,10 @@ func (b *Board) Print() {
 
 func (b *Board) RecomputeHash() uint64 {
 	var h uint64
-	for i, p := range b.Cells {
-		if p != Empty {
-			continue
+	for i, p := range b.occupied() {
+		if p.IsStone() {
+			h ^= b.key(i, p)
 		}
-
-		h ^= b.game.ZobristKeys[i][p.Idx()]
 	}
 
 	return h
@@ -431,87 +718,147 @@ func (b *Board) RecomputeHash() uint64 {
 type Game struct {
 	Board       *Board
 	ZobristKeys [][]uint64
+	ZobristSeed uint64 `json:",omitempty"`
+
+	Phase    Phase
+	Swapped  bool
+	Extended bool
+
+	// Undone holds the plies taken back with Undo, most recent last.
+	Undone []Ply `json:",omitempty"`
+
+	// Names optionally names the participant in each seat.
+	Names []string `json:",omitempty"`
+
+	// ended is set when the game ended by resignation, timeout or forfeit
+	// rather than on the board.
+	ended *Status
 }
 
-func New(N, K int) (*Game, error) {
-	g := Game{
-		ZobristKeys: zobrist.New(N),
+func New(W, H, K int, opts ...Option) (*Game, error) {
+	g := Game{}
+
+	board := Board{
+		W:    W,
+		H:    H,
+		K:    K,
+		game: &g,
+	}
+
+	for _, opt := range opts {
+		opt(&board)
+	}
+
+	if err := board.validateRules(); err != nil {
+		return nil, err
+	}
+
+	if board.Rules.Unbounded {
+		board.W, board.H = 0, 0
+		board.Stones = map[int]Player{}
+		g.ZobristSeed = rand.Uint64()
+	} else {
+		board.Cells = make([]Player, board.W*board.H*board.depth())
+		g.ZobristKeys = zobrist.New(board.W, board.H*board.depth(), len(board.Players()))
 	}
 
-	board := g.newBoard(N, K)
+	board.applySetup()
+	board.init()
 	g.Board = &board
 
+	if board.Rules.Opening != NoOpening {
+		g.Phase = PhaseOpening
+	}
+
 	return &g, nil
 }
 
-func LoadGame(data []byte) (*Game, error) {
-	g := &Game{}
-	err := json.Unmarshal(data, g)
-	if err != nil {
-		return nil, err
-	}
+func (b *Board) validateRules() error {
+	if b.Rules.Unbounded {
+		if b.Rules.Gravity || b.Rules.Wrap {
+			return errors.New("unbounded boards can not have gravity or wrap around")
+		}
 
-	g.Board.game = g
-	N := g.Board.N
+		if b.K < 1 {
+			return fmt.Errorf("invalid win condition %d", b.K)
+		}
+	} else {
+		if b.W < 1 || b.H < 1 {
+			return fmt.Errorf("invalid board size %dx%d", b.W, b.H)
+		}
 
-	xList := make([]int, N*N)
-	for i := range N * N {
-		xList[i] = i % N
+		if b.K < 1 || b.K > max(b.W, b.H, b.depth()) {
+			return fmt.Errorf("invalid win condition %d for board size %dx%d", b.K, b.W, b.H)
+		}
 	}
 
-	yList := make([]int, N*N)
-	for i := range N * N {
-		yList[i] = i / N
+	if b.D < 0 || b.D > 1 && (b.Rules.Unbounded || b.Rules.Gravity || b.Rules.Set == Renju || b.Rules.Ultimate) {
+		return fmt.Errorf("depth %d can not be combined with unbounded, gravity, renju or ultimate", b.D)
 	}
 
-	emptyCells := make([]int, 0, N*N)
-	for i, v := range g.Board.Cells {
-		if v != Empty {
-			continue
-		}
+	if n := b.Rules.Players; n != 0 && (n < 2 || n > MaxPlayers) {
+		return fmt.Errorf("invalid number of players %d", n)
+	}
 
-		emptyCells = append(emptyCells, i)
+	if b.Rules.Opening != NoOpening && len(b.Players()) != 2 {
+		return fmt.Errorf("opening %s needs two players", b.Rules.Opening)
 	}
 
-	g.Board.xList = xList
-	g.Board.yList = yList
-	g.Board.emptyCells = emptyCells
+	if b.Rules.Misere && (len(b.Players()) != 2 || b.Rules.Set == Renju) {
+		return errors.New("misère needs two players and can not be combined with renju")
+	}
 
-	return g, nil
-}
+	if b.Rules.Capture < 0 || b.Rules.Capture > 0 && (b.Rules.Gravity || b.Rules.Misere) {
+		return errors.New("captures need a positive number of pairs and can not be combined with gravity or misère")
+	}
+
+	if b.Rules.Ultimate {
+		others := b.Rules
+		others.Ultimate, others.Players = false, 0
+		if b.W != subSize*subSize || b.H != subSize*subSize || b.K != subSize || others != (Rules{}) || len(b.Players()) != 2 {
+			return errors.New("ultimate is played by two players on a 9x9 board and can not be combined with other variants")
+		}
+	}
 
-func (g *Game) Save() ([]byte, error) {
-	j, err := json.MarshalIndent(g, "", "\t")
-	if err != nil {
-		panic(err)
+	if b.Setup != nil {
+		return b.Setup.validate(b)
 	}
 
-	return j, nil
+	return nil
 }
 
-func (g *Game) newBoard(N, K int) Board {
-	xList := make([]int, N*N)
-	for i := range N * N {
-		xList[i] = i % N
-	}
+func (b *Board) init() {
+	b.countCaptures()
 
-	yList := make([]int, N*N)
-	for i := range N * N {
-		yList[i] = i / N
-	}
+	if b.Rules.Unbounded {
+		if b.Stones == nil {
+			b.Stones = map[int]Player{}
+		}
+
+		b.near = map[int]int{}
+		for idx, p := range b.Stones {
+			if p.IsStone() {
+				b.updateNear(idx, 1)
+			}
+		}
 
-	emptyCells := make([]int, N*N)
-	for i := range emptyCells {
-		emptyCells[i] = i
+		return
 	}
 
-	return Board{
-		N:          N,
-		Cells:      make([]Player, N*N),
-		K:          K,
-		xList:      xList,
-		yList:      yList,
-		emptyCells: emptyCells,
-		game:       g,
+	size := len(b.Cells)
+
+	xList := make([]int, size)
+	yList := make([]int, size)
+	zList := make([]int, size)
+	for i := range size {
+		xList[i] = i % b.W
+		yList[i] = i / b.W % b.H
+		zList[i] = i / (b.W * b.H)
 	}
+
+	b.xList = xList
+	b.yList = yList
+	b.zList = zList
+	b.initBitboards()
+	b.initLines()
 }
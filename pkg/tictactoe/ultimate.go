@@ -0,0 +1,198 @@
+package tictactoe
+
+// subSize is how many cells wide a sub-board of an ultimate board is, and how
+// many sub-boards wide the meta-board is.
+const subSize = 3
+
+// metaLines are the rows, columns and diagonals of the meta-board.
+var metaLines = [][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// WithUltimate plays ultimate tic-tac-toe: nine 3x3 sub-boards in a 3x3
+// meta-board. The cell a stone is played on decides which sub-board the next
+// stone goes in, sub-boards are won by three in a row, and the game by three
+// won sub-boards in a row. The board is always 9x9 with K 3.
+func WithUltimate() Option {
+	return func(b *Board) {
+		b.W, b.H, b.K = subSize*subSize, subSize*subSize, subSize
+		b.Rules.Ultimate = true
+	}
+}
+
+// SubBoard returns which sub-board idx is on, counted row by row.
+func (b *Board) SubBoard(idx int) int {
+	x, y := b.coords(idx)
+	return y/subSize*subSize + x/subSize
+}
+
+// subCells returns the cells of a sub-board.
+func (b *Board) subCells(sub int) [subSize * subSize]int {
+	var cells [subSize * subSize]int
+	x0, y0 := sub%subSize*subSize, sub/subSize*subSize
+	for i := range cells {
+		cells[i] = b.GetIdx(x0+i%subSize, y0+i/subSize)
+	}
+
+	return cells
+}
+
+// SubBoardWinner returns who has three in a row on a sub-board.
+func (b *Board) SubBoardWinner(sub int) Player {
+	for _, idx := range b.subCells(sub) {
+		if p := b.checkFrom(idx); p != Empty {
+			return p
+		}
+	}
+
+	return Empty
+}
+
+// subDecided reports whether a sub-board is won or full.
+func (b *Board) subDecided(sub int) bool {
+	if b.SubBoardWinner(sub) != Empty {
+		return true
+	}
+
+	for _, idx := range b.subCells(sub) {
+		if b.At(idx) == Empty {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ActiveSubBoard returns the sub-board the next stone has to go in, or -1 if
+// any undecided sub-board may be played.
+func (b *Board) ActiveSubBoard() int {
+	if b.Turn == 0 {
+		return -1
+	}
+
+	x, y := b.coords(b.LastMove)
+	sub := y%subSize*subSize + x%subSize
+	if b.subDecided(sub) {
+		return -1
+	}
+
+	return sub
+}
+
+func (b *Board) ultimateLegal(idx int) bool {
+	sub := b.SubBoard(idx)
+	if active := b.ActiveSubBoard(); active >= 0 {
+		return sub == active
+	}
+
+	return !b.subDecided(sub)
+}
+
+// ultimateMask returns the cells of the sub-boards that may be played.
+func (b *Board) ultimateMask() bitboard {
+	if active := b.ActiveSubBoard(); active >= 0 {
+		return b.bits.subs[active]
+	}
+
+	var mask bitboard
+	for sub := range b.bits.subs {
+		if b.subDecided(sub) {
+			continue
+		}
+
+		for i := range mask {
+			mask[i] |= b.bits.subs[sub][i]
+		}
+	}
+
+	return mask
+}
+
+// metaLine returns the won sub-boards that make up the winning line on the
+// meta-board.
+func (b *Board) metaLine() (Player, [3]int) {
+	var winners [subSize * subSize]Player
+	for sub := range winners {
+		winners[sub] = b.SubBoardWinner(sub)
+	}
+
+	for _, line := range metaLines {
+		p := winners[line[0]]
+		if p.IsStone() && winners[line[1]] == p && winners[line[2]] == p {
+			return p, line
+		}
+	}
+
+	return Empty, [3]int{}
+}
+
+// metaWinnable reports whether p can still win three sub-boards in a row.
+func (b *Board) metaWinnable(p Player) bool {
+	var winnable [subSize * subSize]bool
+	for sub := range winnable {
+		winnable[sub] = b.subWinnable(sub, p)
+	}
+
+	for _, line := range metaLines {
+		if winnable[line[0]] && winnable[line[1]] && winnable[line[2]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subWinnable reports whether p has won a sub-board or can still win it. The
+// lines of a sub-board are the same as those of the meta-board.
+func (b *Board) subWinnable(sub int, p Player) bool {
+	switch b.SubBoardWinner(sub) {
+	case p:
+		return true
+	case Empty:
+	default:
+		return false
+	}
+
+	cells := b.subCells(sub)
+	for _, line := range metaLines {
+		open := true
+		for _, i := range line {
+			if q := b.At(cells[i]); q != Empty && q != p {
+				open = false
+			}
+		}
+
+		if open {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ultimateKRow returns the winning rows of the sub-boards on the winning line
+// of the meta-board.
+func (b *Board) ultimateKRow(winner Player) []int {
+	p, line := b.metaLine()
+	if p != winner {
+		return nil
+	}
+
+	var row []int
+	for _, sub := range line {
+		for _, idx := range b.subCells(sub) {
+			if b.At(idx) != winner {
+				continue
+			}
+
+			if r := b.rowFrom(idx, winner); r != nil {
+				row = append(row, r...)
+				break
+			}
+		}
+	}
+
+	return row
+}
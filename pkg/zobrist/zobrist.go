@@ -2,13 +2,24 @@ package zobrist
 
 import "math/rand/v2"
 
-func New(n int) [][]uint64 {
-	zobrist := make([][]uint64, n*n)
-	for i := range n * n {
-		zobrist[i] = make([]uint64, 2) // index by player
-		zobrist[i][0] = rand.Uint64()
-		zobrist[i][1] = rand.Uint64()
+func New(w, h, players int) [][]uint64 {
+	zobrist := make([][]uint64, w*h)
+	for i := range w * h {
+		zobrist[i] = make([]uint64, players) // index by player
+		for p := range players {
+			zobrist[i][p] = rand.Uint64()
+		}
 	}
 
 	return zobrist
 }
+
+// Key derives the key of a player on a cell from seed, for boards too large to
+// hold a table of keys.
+func Key(seed uint64, cell, player int) uint64 {
+	// splitmix64
+	z := seed + uint64(cell)*0x9e3779b97f4a7c15 + uint64(player+1)*0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
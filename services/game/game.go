@@ -1,32 +1,29 @@
 package game
 
 import (
-	"context"
 	"fmt"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
-	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/bot"
 	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
 	"github.com/Zarux/ticntacntoen/services/game/game"
 	"github.com/Zarux/ticntacntoen/services/game/settings"
 )
 
-type botPlayer interface {
-	GetNextMove(context.Context, *tictactoe.Board, tictactoe.Player) (int, error)
-	Stats() *mcts.LastMoveStats
-	UpdateThinkTime(t time.Duration)
-}
-
 type Service struct {
-	bot botPlayer
+	workers    int
+	iterations int
 }
 
-func New(bot botPlayer) *Service {
+// New returns a service whose bots search with workers each running up to
+// iterations iterations. Which bot is played against is picked in the
+// settings.
+func New(workers, iterations int) *Service {
 	return &Service{
-		bot: bot,
+		workers:    workers,
+		iterations: iterations,
 	}
 }
 
@@ -42,11 +39,22 @@ func (s *Service) Play() {
 		return
 	}
 
-	s.bot.UpdateThinkTime(settings.ThinkTime)
+	b := bot.New(settings.Bot, s.workers, s.iterations)
+	b.UpdateThinkTime(settings.ThinkTime)
 
 	for {
-		g, _ := tictactoe.New(settings.N, settings.K)
-		gameModel := game.InitialModel(header(), g.Board, s.bot, settings.P)
+		g, err := tictactoe.New(settings.W, settings.H, settings.K, settings.Options()...)
+		if err != nil {
+			panic(err)
+		}
+
+		g.Names = make([]string, len(g.Board.Players()))
+		for i := range g.Names {
+			g.Names[i] = "bot"
+		}
+		g.Names[g.Seat(settings.P)] = "human"
+
+		gameModel := game.InitialModel(header(), g, b, settings.P, settings.ThinkTime)
 
 		p = tea.NewProgram(gameModel, tea.WithAltScreen(), tea.WithoutCatchPanics())
 		if _, err := p.Run(); err != nil {
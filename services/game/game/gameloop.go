@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"math/rand/v2"
+	"os"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -21,32 +23,76 @@ type botPlayer interface {
 	Stats() *mcts.LastMoveStats
 }
 
+type openingChooser interface {
+	ChooseOpening(context.Context, *tictactoe.Game) (tictactoe.Choice, error)
+}
+
+// viewportSize is how many rows and columns of an unbounded board are shown.
+const viewportSize = 15
+
+// clockGrace is how much longer than its think time the bot may take for a
+// move, for the search to wind down, before it loses on time.
+const clockGrace = 2 * time.Second
+
+var arrowSteps = map[string]tictactoe.Move{
+	"left":  {X: -1},
+	"right": {X: 1},
+	"up":    {Y: -1},
+	"down":  {Y: 1},
+}
+
 type model struct {
+	game          *tictactoe.Game
 	board         *tictactoe.Board
 	cursor        int
 	currentPlayer tictactoe.Player
-	botPlayer     tictactoe.Player
+	humanSeat     tictactoe.Seat
 	bot           botPlayer
 	spinner       spinner.Model
 	sub           chan botDoneMsg
 	header        string
 
-	gameOver bool
-	winner   tictactoe.Player
-	Replay   bool
+	// clock is how long the bot has for a move, with no limit if it is 0.
+	clock time.Duration
+
+	viewX int
+	viewY int
+
+	// typing is set while a move is typed in notation after pressing ':'.
+	typing   bool
+	input    string
+	inputErr error
+
+	// hints is toggled with 'h' and marks cells worth a look for the player
+	// to move.
+	hints bool
+
+	// saved is the file the finished game was written to with 's'.
+	saved   string
+	saveErr error
+
+	status tictactoe.Status
+	Replay bool
 }
 
 func (m model) Init() tea.Cmd {
-	if m.bot != nil && m.botPlayer == m.currentPlayer {
+	if m.botTurn() {
 		return tea.Batch(m.beginTick(), waitForBot(m.sub), m.botMove(context.Background(), m.sub))
 	}
 
 	return nil
 }
 
+func (m model) botTurn() bool {
+	return m.bot != nil && m.game.SeatToMove() != m.humanSeat && !m.status.Over()
+}
+
 var (
 	p1Style              = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#007e50ff", Dark: "#6afd76ff"}).Render
 	p2Style              = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#0003adff", Dark: "#5f61fcff"}).Render
+	p3Style              = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8a0f7eff", Dark: "#e86ef0ff"}).Render
+	p4Style              = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8a5a00ff", Dark: "#f5b942ff"}).Render
+	blockedStyle         = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#5f5f5fff", Dark: "#5f5f5fff"}).Render
 	cursorStyle          = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#960000ff", Dark: "#fc7e7eff"}).Render
 	winningRowStyle      = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#bb0000ff", Dark: "#df1010ff"}).Render
 	lastWinningRowStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#f80000ff", Dark: "#f18787ff"}).Render
@@ -56,65 +102,137 @@ var (
 	statStyle2           = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#138a0fff", Dark: "#1ddd37ff"}).Render
 )
 
+func playerStyle(p tictactoe.Player) func(strs ...string) string {
+	switch p {
+	case tictactoe.P1:
+		return p1Style
+	case tictactoe.P2:
+		return p2Style
+	case tictactoe.P3:
+		return p3Style
+	case tictactoe.P4:
+		return p4Style
+	}
+
+	return bracketStyle
+}
+
 var thinkingColors = []func(strs ...string) string{
 	bracketStyle,
 	lastMoveBracketStyle,
 }
 
-func InitialModel(header string, b *tictactoe.Board, bot botPlayer, playerStone tictactoe.Player) *model {
+// InitialModel returns a game of the human playing playerStone against bot,
+// which loses on time if it takes much longer than thinkTime for a move.
+func InitialModel(header string, g *tictactoe.Game, bot botPlayer, playerStone tictactoe.Player, thinkTime time.Duration) *model {
 	s := spinner.New()
 	s.Spinner = spinner.Points
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
-	return &model{
+	b := g.Board
+
+	cursor := 0
+	if b.Rules.Gravity {
+		cursor, _ = b.Drop(0)
+	}
+
+	if b.Rules.Unbounded {
+		cursor = b.GetIdx(0, 0)
+	}
+
+	m := &model{
+		game:          g,
 		board:         b,
-		currentPlayer: tictactoe.P1,
-		botPlayer:     -playerStone,
+		cursor:        cursor,
+		currentPlayer: b.ToMove(),
+		humanSeat:     g.Seat(playerStone),
 		bot:           bot,
 		spinner:       s,
 		sub:           make(chan botDoneMsg),
 		Replay:        false,
 		header:        header,
+		viewX:         -viewportSize / 2,
+		viewY:         -viewportSize / 2,
 	}
+
+	if thinkTime > 0 {
+		m.clock = thinkTime + clockGrace
+	}
+
+	// The setup may have put something on the first cell.
+	if !b.Rules.Gravity && !b.Rules.Unbounded && b.At(cursor) != tictactoe.Empty {
+		m.cursor, _ = m.moveRight()
+	}
+
+	return m
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case botDoneMsg:
-		if msg.draw {
-			m.gameOver = true
-			m.winner = tictactoe.Empty
+		m.status = msg.status
+		if m.status.Over() {
 			return m, nil
 		}
 
-		if msg.winner != tictactoe.Empty {
-			m.gameOver = true
-			m.winner = msg.winner
-			return m, nil
+		m.cursor = msg.cursor
+		m.currentPlayer = m.board.ToMove()
+		if m.board.Rules.Unbounded && !m.visible(m.board.LastMove) {
+			m.scrollTo(m.board.LastMove)
+			m.cursor = m.board.LastMove
+		}
+
+		if m.botTurn() {
+			return m, tea.Batch(waitForBot(m.sub), m.botMove(context.Background(), m.sub))
 		}
 
-		m.cursor = msg.cursor
-		m.currentPlayer = -m.currentPlayer
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.typing {
+			return m.typeMove(msg)
+		}
+
+		if step, ok := arrowSteps[msg.String()]; ok && m.board.Rules.Unbounded {
+			mv := m.board.GetMove(m.cursor)
+			if m.board.InBounds(mv.X+step.X, mv.Y+step.Y) {
+				m.cursor = m.board.GetIdx(mv.X+step.X, mv.Y+step.Y)
+				m.scrollTo(m.cursor)
+			}
+
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
 		case "right":
 			cursor, _ := m.moveRight()
+			if m.board.Rules.Gravity {
+				cursor, _ = m.moveColumn(1)
+			}
+
 			m.cursor = cursor
 		case "left":
 			cursor, _ := m.moveLeft()
+			if m.board.Rules.Gravity {
+				cursor, _ = m.moveColumn(-1)
+			}
+
 			m.cursor = cursor
 		case "up":
-			if m.cursor > m.board.N-1 {
+			if m.board.Rules.Gravity {
+				return m, nil
+			}
+
+			start, _ := m.layerCells()
+			if m.cursor-start > m.board.W-1 {
 				oCursor := m.cursor
-				m.cursor -= m.board.N
+				m.cursor -= m.board.W
 				for {
-					if m.cursor < 0 {
+					if m.cursor < start {
 						m.cursor = oCursor
 						return m, nil
 					}
@@ -123,15 +241,20 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						break
 					}
 
-					m.cursor -= m.board.N
+					m.cursor -= m.board.W
 				}
 			}
 		case "down":
-			if m.cursor < m.board.N*(m.board.N-1) {
+			if m.board.Rules.Gravity {
+				return m, nil
+			}
+
+			_, end := m.layerCells()
+			if m.cursor < end-m.board.W {
 				oCursor := m.cursor
-				m.cursor += m.board.N
+				m.cursor += m.board.W
 				for {
-					if m.cursor > len(m.board.Cells)-1 {
+					if m.cursor > end-1 {
 						m.cursor = oCursor
 						return m, nil
 					}
@@ -140,40 +263,98 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						break
 					}
 
-					m.cursor += m.board.N
+					m.cursor += m.board.W
 				}
 			}
+		case "[", "]":
+			if m.board.D <= 1 || m.cursor < 0 {
+				return m, nil
+			}
+
+			mv := m.board.GetMove(m.cursor)
+			mv.Z += map[string]int{"[": -1, "]": 1}[msg.String()]
+			if m.board.InBounds3(mv.X, mv.Y, mv.Z) {
+				m.cursor = m.board.GetIdx3(mv.X, mv.Y, mv.Z)
+			}
 		case "enter":
-			if m.gameOver {
+			if m.status.Over() {
 				m.Replay = true
 				return m, tea.Quit
 			}
 
-			if m.bot != nil && m.currentPlayer == m.botPlayer {
+			return m, m.submit()
+
+		case ":":
+			if m.status.Over() || m.botTurn() {
+				return m, nil
+			}
+
+			m.typing = true
+			m.input = ""
+			m.inputErr = nil
+
+		case "s":
+			if !m.status.Over() {
+				return m, nil
+			}
+
+			m.saved, m.saveErr = m.saveRecord()
+			return m, nil
+
+		case "h":
+			m.hints = !m.hints
+
+		case "R":
+			if m.botTurn() || m.status.Over() {
+				return m, nil
+			}
+
+			if err := m.game.Resign(m.game.Color(m.humanSeat)); err != nil {
+				panic(err)
+			}
+
+			m.status = m.game.Status()
+			m.cursor = -1
+			return m, nil
+
+		case "u":
+			if m.botTurn() {
+				return m, nil
+			}
+
+			return m, m.takeBack(m.game.Undo)
+
+		case "r":
+			if m.botTurn() {
 				return m, nil
 			}
 
-			newCursor, winner := m.playerMove(m.cursor, m.currentPlayer)
-			if !m.board.AnyLegalMoves() && winner == tictactoe.Empty {
-				m.gameOver = true
-				m.winner = tictactoe.Empty
+			return m, m.takeBack(m.game.Redo)
+
+		case "x", "o", "p":
+			if m.botTurn() || m.game.Phase != tictactoe.PhaseChoose {
 				return m, nil
 			}
 
-			if winner == m.currentPlayer {
-				m.gameOver = true
-				m.winner = m.currentPlayer
+			choice := map[string]tictactoe.Choice{
+				"x": tictactoe.ChooseP1,
+				"o": tictactoe.ChooseP2,
+				"p": tictactoe.ChoosePlaceTwo,
+			}[msg.String()]
+
+			if !slices.Contains(m.game.Choices(), choice) {
 				return m, nil
 			}
 
-			m.cursor = newCursor
-			m.currentPlayer = -m.currentPlayer
+			if err := m.game.Choose(choice); err != nil {
+				panic(err)
+			}
 
-			return m, tea.Batch(m.beginTick(), waitForBot(m.sub), m.botMove(context.Background(), m.sub))
+			return m, m.startBot()
 		}
 
 	default:
-		if m.gameOver {
+		if m.status.Over() {
 			m.cursor = -1
 			return m, nil
 		}
@@ -186,35 +367,141 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m model) playerMove(move int, p tictactoe.Player) (int, tictactoe.Player) {
-	err := m.board.ApplyMove(move, p)
+// submit plays the cell under the cursor.
+func (m *model) submit() tea.Cmd {
+	if m.botTurn() || m.game.Phase == tictactoe.PhaseChoose || !m.board.IsLegal(m.cursor) {
+		return nil
+	}
+
+	newCursor := m.playerMove(m.cursor)
+	m.status = m.game.Status()
+	if m.status.Over() {
+		return nil
+	}
+
+	m.cursor = newCursor
+	m.currentPlayer = m.board.ToMove()
+
+	return m.startBot()
+}
+
+func (m *model) typeMove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.typing = false
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyEnter:
+		idx, err := m.board.ParseMove(m.input)
+		if err == nil && !m.board.IsLegal(idx) {
+			err = fmt.Errorf("%s can not be played", m.board.FormatMove(idx))
+		}
+
+		m.inputErr = err
+		if err != nil {
+			return m, nil
+		}
+
+		m.typing = false
+		m.cursor = idx
+		if m.board.Rules.Unbounded {
+			m.scrollTo(idx)
+		}
+
+		return m, m.submit()
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+
+	return m, nil
+}
+
+// saveRecord writes the game as SGF to the working directory.
+func (m model) saveRecord() (string, error) {
+	data, err := m.game.SGF()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("ticntacntoen-%s.sgf", time.Now().Format("20060102-150405"))
+	return name, os.WriteFile(name, data, 0o644)
+}
+
+func (m model) startBot() tea.Cmd {
+	if !m.botTurn() {
+		return nil
+	}
+
+	return tea.Batch(m.beginTick(), waitForBot(m.sub), m.botMove(context.Background(), m.sub))
+}
+
+// takeBack undoes or redoes plies with step until it is the human's turn
+// again, and hands the turn to the bot if the history runs out on its turn.
+func (m *model) takeBack(step func() error) tea.Cmd {
+	for {
+		if err := step(); err != nil {
+			break
+		}
+
+		if m.bot == nil || m.game.SeatToMove() == m.humanSeat {
+			break
+		}
+	}
+
+	m.saved, m.saveErr = "", nil
+	m.currentPlayer = m.board.ToMove()
+	m.status = m.game.Status()
+
+	m.cursor = m.board.LastMove
+	switch {
+	case m.board.Rules.Unbounded:
+		m.scrollTo(m.cursor)
+	case m.board.Rules.Gravity:
+		m.cursor, _ = m.columnCursor(m.board.GetMove(m.cursor).X)
+	case m.board.At(m.cursor) != tictactoe.Empty:
+		m.cursor, _ = m.moveRight()
+	}
+
+	return m.startBot()
+}
+
+func (m model) playerMove(move int) int {
+	err := m.game.PlayMove(move)
 	if err != nil {
 		panic(err)
 	}
 
-	winner := m.board.CheckWinner()
+	if m.board.Rules.Gravity {
+		cursor, _ := m.columnCursor(m.board.GetMove(max(m.cursor, 0)).X)
+		return cursor
+	}
+
+	if m.board.Rules.Unbounded {
+		return m.cursor
+	}
 
 	if m.cursor != move {
-		return m.cursor, winner
+		return m.cursor
 	}
 
 	cursor, rOk := m.moveRight()
 	if rOk {
-		return cursor, winner
+		return cursor
 	}
 
 	cursor, lOk := m.moveLeft()
 	if lOk {
-		return cursor, winner
+		return cursor
 	}
 
-	return -1, winner
+	return -1
 }
 
 type botDoneMsg struct {
 	cursor int
-	winner tictactoe.Player
-	draw   bool
+	status tictactoe.Status
 }
 
 func waitForBot(sub chan botDoneMsg) tea.Cmd {
@@ -231,16 +518,43 @@ func (m model) beginTick() tea.Cmd {
 
 func (m model) botMove(ctx context.Context, sub chan botDoneMsg) tea.Cmd {
 	return func() tea.Msg {
-		nextMove, err := m.bot.GetNextMove(ctx, m.board, m.botPlayer)
+		if m.game.Phase == tictactoe.PhaseChoose {
+			choice := m.game.Choices()[0]
+			if chooser, ok := m.bot.(openingChooser); ok {
+				var err error
+				choice, err = chooser.ChooseOpening(ctx, m.game)
+				if err != nil {
+					panic(err)
+				}
+			}
+
+			if err := m.game.Choose(choice); err != nil {
+				panic(err)
+			}
+
+			sub <- botDoneMsg{cursor: m.cursor, status: m.game.Status()}
+			return nil
+		}
+
+		start := time.Now()
+		player := m.board.ToMove()
+		nextMove, err := m.bot.GetNextMove(ctx, m.board, player)
 		if err != nil {
 			panic(err)
 		}
 
-		cursor, winner := m.playerMove(nextMove, m.botPlayer)
+		if m.clock > 0 && time.Since(start) > m.clock {
+			if err := m.game.TimeOut(player); err != nil {
+				panic(err)
+			}
+
+			sub <- botDoneMsg{cursor: -1, status: m.game.Status()}
+			return nil
+		}
+
 		sub <- botDoneMsg{
-			cursor: cursor,
-			winner: winner,
-			draw:   !m.board.AnyLegalMoves() && winner == tictactoe.Empty,
+			cursor: m.playerMove(nextMove),
+			status: m.game.Status(),
 		}
 
 		return nil
@@ -289,19 +603,167 @@ func (m model) moveLeft() (int, bool) {
 	return m.cursor, m.cursor != oCursor
 }
 
+func (m model) moveColumn(dir int) (int, bool) {
+	for x := m.board.GetMove(m.cursor).X + dir; x >= 0 && x < m.board.W; x += dir {
+		if idx, ok := m.board.Drop(x); ok {
+			return idx, true
+		}
+	}
+
+	return m.cursor, false
+}
+
+func (m model) columnCursor(x int) (int, bool) {
+	for d := range m.board.W {
+		if idx, ok := m.board.Drop(x + d); ok {
+			return idx, true
+		}
+
+		if idx, ok := m.board.Drop(x - d); ok {
+			return idx, true
+		}
+	}
+
+	return -1, false
+}
+
+func (m model) renderCell(i int, botTurn bool, highlights []int, hints map[int]string) string {
+	p := m.board.At(i)
+	mark := p.Mark()
+	if hint, ok := hints[i]; ok && p == tictactoe.Empty {
+		mark = hint
+	}
+
+	if m.cursor == i {
+		mark = cursorStyle("*")
+	}
+
+	if botTurn && p == tictactoe.Empty {
+		mark = []string{"o", "x", " ", " "}[rand.N(4)]
+		mark = thinkingColors[rand.IntN(len(thinkingColors))](mark)
+	}
+
+	switch p {
+	case tictactoe.P1:
+		mark = p1Style(p.Mark())
+	case tictactoe.P2:
+		mark = p2Style(p.Mark())
+	case tictactoe.P3:
+		mark = p3Style(p.Mark())
+	case tictactoe.P4:
+		mark = p4Style(p.Mark())
+	case tictactoe.Blocked:
+		mark = blockedStyle(p.Mark())
+	}
+
+	bStyle := bracketStyle
+	if m.board.Rules.Ultimate {
+		bStyle = m.subBoardStyle(i)
+	}
+
+	winningRow := slices.Contains(highlights, i)
+
+	if winningRow {
+		bStyle = winningRowStyle
+	}
+
+	if m.board.Turn > 0 && m.board.LastMove == i && p != tictactoe.Empty {
+		bStyle = lastMoveBracketStyle
+		if winningRow {
+			bStyle = lastWinningRowStyle
+		}
+	}
+
+	return fmt.Sprintf("%s%s%s", bStyle("["), mark, bStyle("]"))
+}
+
+// hintMarks marks the cells where an opponent would complete a row with '!'
+// and the cells that make a fork for the player to move with '+'.
+func (m model) hintMarks() map[int]string {
+	// Under misère rules rows are to be avoided, so the marks would mislead.
+	if m.board.Rules.Misere {
+		return nil
+	}
+
+	player := m.board.ToMove()
+	marks := map[int]string{}
+	for _, idx := range m.board.ForkMoves(player) {
+		marks[idx] = statStyle1("+")
+	}
+
+	for _, opponent := range m.board.Players() {
+		if opponent == player {
+			continue
+		}
+
+		for _, idx := range m.board.WinningCells(opponent) {
+			marks[idx] = cursorStyle("!")
+		}
+	}
+
+	return marks
+}
+
+// subBoardStyle colors the brackets of a won sub-board as its winner and
+// highlights the sub-boards the next stone may go in.
+func (m model) subBoardStyle(i int) func(strs ...string) string {
+	sub := m.board.SubBoard(i)
+	if w := m.board.SubBoardWinner(sub); w != tictactoe.Empty {
+		return playerStyle(w)
+	}
+
+	if active := m.board.ActiveSubBoard(); !m.status.Over() && (active == sub || active == -1) {
+		return statStyle1
+	}
+
+	return bracketStyle
+}
+
+// layerCells returns the range of cells on the cursor's layer.
+func (m model) layerCells() (int, int) {
+	size := m.board.W * m.board.H
+	start := m.board.Layer(max(m.cursor, 0)) * size
+	return start, start + size
+}
+
+// cellLabel returns the notation of the cell without its layer.
+func (m model) cellLabel(idx int) string {
+	label, _, _ := strings.Cut(m.board.FormatMove(idx), ":")
+	return label
+}
+
+// view returns the part of the board that is drawn.
+func (m model) view() (minX, minY, maxX, maxY int) {
+	if !m.board.Rules.Unbounded {
+		return m.board.Bounds()
+	}
+
+	return m.viewX, m.viewY, m.viewX + viewportSize - 1, m.viewY + viewportSize - 1
+}
+
+// scrollTo moves the viewport of an unbounded board just enough to show idx.
+func (m *model) scrollTo(idx int) {
+	mv := m.board.GetMove(idx)
+	m.viewX = min(max(m.viewX, mv.X-viewportSize+1), mv.X)
+	m.viewY = min(max(m.viewY, mv.Y-viewportSize+1), mv.Y)
+}
+
+func (m model) visible(idx int) bool {
+	mv := m.board.GetMove(idx)
+	minX, minY, maxX, maxY := m.view()
+	return mv.X >= minX && mv.X <= maxX && mv.Y >= minY && mv.Y <= maxY
+}
+
 func (m model) View() string {
-	if m.gameOver && m.Replay {
+	if m.status.Over() && m.Replay {
 		return ""
 	}
 
-	var highlights []int
-	if m.gameOver && m.winner != tictactoe.Empty {
-		highlights = m.board.GetKRow(m.winner)
-	}
+	highlights := m.status.Line
 
 	s := m.header
 
-	botTurn := m.bot != nil && m.currentPlayer == m.botPlayer && !m.gameOver
+	botTurn := m.botTurn()
 
 	s += "Current player: "
 	switch m.currentPlayer {
@@ -309,6 +771,14 @@ func (m model) View() string {
 		s += p1Style(m.currentPlayer.Mark())
 	case tictactoe.P2:
 		s += p2Style(m.currentPlayer.Mark())
+	case tictactoe.P3:
+		s += p3Style(m.currentPlayer.Mark())
+	case tictactoe.P4:
+		s += p4Style(m.currentPlayer.Mark())
+	}
+
+	if left := m.board.StonesLeft(); left > 1 && !m.status.Over() {
+		s += fmt.Sprintf(" (%d stones left)", left)
 	}
 
 	if botTurn {
@@ -317,51 +787,132 @@ func (m model) View() string {
 
 	s += "\n"
 
-	for i, p := range m.board.Cells {
-		mark := p.Mark()
-		if m.cursor == i {
-			mark = cursorStyle("*")
+	if m.board.Rules.Wrap {
+		s += bracketStyle("Lines wrap around the edges") + "\n"
+	}
+
+	if m.board.Rules.Misere {
+		s += bracketStyle("Misère: completing a row loses") + "\n"
+	}
+
+	if m.board.Rules.Capture > 0 {
+		s += "Captures:"
+		for _, p := range m.board.Players() {
+			s += fmt.Sprintf(" %s %d/%d", playerStyle(p)(p.Mark()), m.board.Captures(p), m.board.Rules.Capture)
 		}
 
-		if botTurn && p == tictactoe.Empty {
-			mark = []string{"o", "x", " ", " "}[rand.N(4)]
-			mark = thinkingColors[rand.IntN(len(thinkingColors))](mark)
+		s += "\n"
+	}
+
+	switch m.game.Phase {
+	case tictactoe.PhaseOpening:
+		s += fmt.Sprintf("Opening (%s): placing opening stones\n", m.board.Rules.Opening)
+	case tictactoe.PhaseChoose:
+		s += fmt.Sprintf("Opening (%s): choose ", m.board.Rules.Opening)
+		for _, c := range m.game.Choices() {
+			key := map[tictactoe.Choice]string{
+				tictactoe.ChooseP1:       "x",
+				tictactoe.ChooseP2:       "o",
+				tictactoe.ChoosePlaceTwo: "p",
+			}[c]
+
+			s += fmt.Sprintf("[%s] %s  ", statStyle1(key), c)
 		}
 
-		switch p {
-		case tictactoe.P1:
-			mark = p1Style(p.Mark())
-		case tictactoe.P2:
-			mark = p2Style(p.Mark())
+		s += "\n"
+	}
+
+	var hints map[int]string
+	if m.hints && !botTurn && !m.status.Over() {
+		hints = m.hintMarks()
+	}
+
+	labels := !m.board.Rules.Unbounded
+	minX, minY, maxX, maxY := m.view()
+
+	// Layers of a 3D board are drawn side by side.
+	layers := max(1, m.board.D)
+	if layers > 1 {
+		for z := range layers {
+			style := bracketStyle
+			if m.cursor >= 0 && m.board.Layer(m.cursor) == z {
+				style = statStyle1
+			}
+
+			s += style(fmt.Sprintf("%-*s", (maxX-minX+1)*3+2, fmt.Sprintf(" layer %d", z+1)))
 		}
 
-		bStyle := bracketStyle
-		winningRow := slices.Contains(highlights, i)
+		s += "\n"
+	}
 
-		if winningRow {
-			bStyle = winningRowStyle
+	for y := minY; y <= maxY; y++ {
+		if m.board.Rules.Ultimate && y > minY && y%3 == 0 {
+			s += "\n"
 		}
 
-		if m.board.Turn > 0 && m.board.LastMove == i && p != tictactoe.Empty {
-			bStyle = lastMoveBracketStyle
-			if winningRow {
-				bStyle = lastWinningRowStyle
+		for z := range layers {
+			if z > 0 {
+				s += "  "
+			}
+
+			for x := minX; x <= maxX; x++ {
+				if m.board.Rules.Ultimate && x > minX && x%3 == 0 {
+					s += " "
+				}
+
+				s += m.renderCell(m.board.GetIdx3(x, y, z), botTurn, highlights, hints)
 			}
 		}
 
-		s += fmt.Sprintf("%s%s%s", bStyle("["), mark, bStyle("]"))
-		if (i+1)%m.board.N == 0 {
-			s += "\n"
+		if labels {
+			row := strings.TrimLeft(m.cellLabel(m.board.GetIdx(minX, y)), "abcdefghijklmnopqrstuvwxyz")
+			s += " " + bracketStyle(row)
+		}
+
+		s += "\n"
+	}
+
+	if labels {
+		for z := range layers {
+			if z > 0 {
+				s += "  "
+			}
+
+			for x := minX; x <= maxX; x++ {
+				if m.board.Rules.Ultimate && x > minX && x%3 == 0 {
+					s += " "
+				}
+
+				col := strings.TrimRight(m.cellLabel(m.board.GetIdx(x, maxY)), "0123456789")
+				s += bracketStyle(fmt.Sprintf("%-3s", " "+col))
+			}
+		}
+
+		s += "\n"
+	}
+
+	if !m.typing && !m.status.Over() {
+		hint := "[:] type a move  [u] undo  [r] redo  [h] hints  [R] resign"
+		if layers > 1 {
+			hint += "  [[ ]] change layer"
+		}
+
+		s += bracketStyle(hint) + "\n"
+	}
+
+	if m.typing {
+		s += fmt.Sprintf("Move: %s%s\n", m.input, cursorStyle("_"))
+		if m.inputErr != nil {
+			s += cursorStyle(m.inputErr.Error()) + "\n"
 		}
 	}
 
 	stats := m.bot.Stats()
-	if m.currentPlayer != m.botPlayer && stats != nil {
+	if !botTurn && stats != nil {
 		s += "\n"
-		m := m.board.GetMove(stats.BestMove)
 		s += fmt.Sprintf(
 			"Found move: %s\nDid %s iterations over %s (Total: %s)\nMost visited node for move across workers: Visits: %s - Score: %s\n",
-			statStyle1(fmt.Sprintf("(%d, %d)", m.X+1, m.Y+1)),
+			statStyle1(m.board.FormatMove(stats.BestMove)),
 			statStyle2(strconv.Itoa(stats.NumIterations)),
 			statStyle2(stats.RealThinkTime.Round(time.Millisecond).String()),
 			statStyle2(stats.ActualThinkTime.Round(time.Millisecond).String()),
@@ -378,20 +929,39 @@ func (m model) View() string {
 		}
 	}
 
-	if m.gameOver {
+	if m.status.Over() {
 		s += "\n" + gameOverText
+		switch {
+		case m.status.Outcome == tictactoe.Drawn && m.board.AnyLegalMoves():
+			s += "\nNo row can be made any more, the game is a draw"
+		case m.status.Loser != tictactoe.Empty:
+			s += fmt.Sprintf("\n%s %s", m.status.Loser.Mark(), m.status.Outcome)
+		}
+
+		switch {
+		case m.saveErr != nil:
+			s += "\n" + cursorStyle(m.saveErr.Error())
+		case m.saved != "":
+			s += "\nSaved to " + statStyle1(m.saved)
+		default:
+			s += "\n" + bracketStyle("[s] save as SGF  [enter] play again")
+		}
 
 		s += "\nTHE WINNER IS: "
-		if m.winner == tictactoe.Empty {
+		if m.status.Winner == tictactoe.Empty {
 			s += cursorStyle("NO ONE\n")
 			return s
 		}
 
-		switch m.winner {
+		switch m.status.Winner {
 		case tictactoe.P1:
-			s += p1Style(m.winner.Mark())
+			s += p1Style(m.status.Winner.Mark())
 		case tictactoe.P2:
-			s += p2Style(m.winner.Mark())
+			s += p2Style(m.status.Winner.Mark())
+		case tictactoe.P3:
+			s += p3Style(m.status.Winner.Mark())
+		case tictactoe.P4:
+			s += p4Style(m.status.Winner.Mark())
 		}
 
 		s += "\n"
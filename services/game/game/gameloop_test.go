@@ -0,0 +1,81 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+// testBot plays the first legal move after thinking for delay.
+type testBot struct {
+	delay time.Duration
+}
+
+func (b testBot) GetNextMove(ctx context.Context, board *tictactoe.Board, p tictactoe.Player) (int, error) {
+	time.Sleep(b.delay)
+	return board.LegalMoves()[0], nil
+}
+
+func (b testBot) Stats() *mcts.LastMoveStats {
+	return nil
+}
+
+func newTestModel(t *testing.T, bot testBot, human tictactoe.Player, thinkTime time.Duration) *model {
+	t.Helper()
+
+	g, err := tictactoe.New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return InitialModel("", g, bot, human, thinkTime)
+}
+
+func TestResignKey(t *testing.T) {
+	m := newTestModel(t, testBot{}, tictactoe.P1, time.Second)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if s := m.game.Status(); s.Outcome != tictactoe.Resigned || s.Loser != tictactoe.P1 || s.Winner != tictactoe.P2 {
+		t.Errorf("game %s with loser %s and winner %s, expected X to resign to O", s.Outcome, s.Loser.Mark(), s.Winner.Mark())
+	}
+
+	if !m.status.Over() {
+		t.Error("the board does not show the game is over")
+	}
+}
+
+func TestBotClock(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		outcome tictactoe.Outcome
+	}{
+		{"in time", 0, tictactoe.Ongoing},
+		{"out of time", 50 * time.Millisecond, tictactoe.TimedOut},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The bot is X and moves first.
+			m := newTestModel(t, testBot{delay: tt.delay}, tictactoe.P2, time.Second)
+			m.clock = 20 * time.Millisecond
+
+			go m.botMove(context.Background(), m.sub)()
+			m.Update(<-m.sub)
+
+			s := m.game.Status()
+			if s.Outcome != tt.outcome {
+				t.Fatalf("game %s, expected %s", s.Outcome, tt.outcome)
+			}
+
+			if tt.outcome == tictactoe.TimedOut && (s.Loser != tictactoe.P1 || m.board.Turn != 0) {
+				t.Errorf("%s lost on time after %d plies, expected X before its move", s.Loser.Mark(), m.board.Turn)
+			}
+		})
+	}
+}
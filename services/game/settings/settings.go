@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Zarux/ticntacntoen/pkg/bot"
 	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,22 +18,88 @@ var (
 var nChoiceRange = []int{3, 15}
 var kChoiceRange = []int{3, 6}
 var timeChoiceRange = []int{1, 60}
-var pChoiceRange = []int{0, 1}
+var playersChoiceRange = []int{2, tictactoe.MaxPlayers}
+var stoneChoices = []tictactoe.Player{tictactoe.P1, tictactoe.P2, tictactoe.P3, tictactoe.P4}
+var ruleChoices = []string{"Freestyle", "Exact", "Renju", "Gravity", "Connect6", "Torus", "Unbounded", "Misère", "Pente", "Ultimate", "Cube"}
+var botChoiceNames = map[bot.Kind]string{bot.MCTS: "Monte Carlo search", bot.Solver: "Solver (perfect on small boards)"}
+var openingChoices = []tictactoe.Opening{tictactoe.NoOpening, tictactoe.Pie, tictactoe.Swap, tictactoe.Swap2}
 
 type settings struct {
-	N         int
+	Players   int
+	W         int
+	H         int
 	K         int
 	ThinkTime time.Duration
 	P         tictactoe.Player
+	RuleSet   tictactoe.RuleSet
+	Gravity   bool
+	Connect6  bool
+	Torus     bool
+	Unbounded bool
+	Misere    bool
+	Pente     bool
+	Ultimate  bool
+	Cube      bool
+	Opening   tictactoe.Opening
+	Bot       bot.Kind
+}
+
+func (s *settings) Options() []tictactoe.Option {
+	opts := []tictactoe.Option{
+		tictactoe.WithRuleSet(s.RuleSet),
+		tictactoe.WithPlayers(s.Players),
+	}
+	if s.Gravity {
+		opts = append(opts, tictactoe.WithGravity())
+	}
+
+	if s.Connect6 {
+		opts = append(opts, tictactoe.WithStonesPerTurn(2, 1))
+	}
+
+	if s.Torus {
+		opts = append(opts, tictactoe.WithWrap())
+	}
+
+	if s.Unbounded {
+		opts = append(opts, tictactoe.WithUnbounded())
+	}
+
+	if s.Misere {
+		opts = append(opts, tictactoe.WithMisere())
+	}
+
+	if s.Pente {
+		opts = append(opts, tictactoe.WithCaptures(5))
+	}
+
+	if s.Ultimate {
+		opts = append(opts, tictactoe.WithUltimate())
+	}
+
+	if s.Cube {
+		opts = append(opts, tictactoe.WithDepth(s.W))
+	}
+
+	if s.Opening != tictactoe.NoOpening {
+		opts = append(opts, tictactoe.WithOpening(s.Opening))
+	}
+
+	return opts
 }
 
 type choiceLevel int
 
 const (
-	choiceLevelP choiceLevel = iota
-	choiceLevelN
+	choiceLevelPlayers choiceLevel = iota
+	choiceLevelP
+	choiceLevelW
+	choiceLevelH
 	choiceLevelK
+	choiceLevelRules
+	choiceLevelOpening
 	choiceLevelThink
+	choiceLevelBot
 )
 
 type model struct {
@@ -66,20 +133,42 @@ func (m *model) Init() tea.Cmd {
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var choices []int
+	if m.choiceLevel == choiceLevelPlayers {
+		for i := playersChoiceRange[0]; i <= playersChoiceRange[1]; i++ {
+			choices = append(choices, i)
+		}
+	}
+
 	if m.choiceLevel == choiceLevelP {
-		for i := pChoiceRange[0]; i <= pChoiceRange[1]; i++ {
+		for i := range m.settings.Players {
 			choices = append(choices, i)
 		}
 	}
 
-	if m.choiceLevel == choiceLevelN {
+	if m.choiceLevel == choiceLevelW || m.choiceLevel == choiceLevelH {
 		for i := nChoiceRange[0]; i <= nChoiceRange[1]; i++ {
 			choices = append(choices, i)
 		}
 	}
 
 	if m.choiceLevel == choiceLevelK {
-		for i := kChoiceRange[0]; i <= kChoiceRange[1] && i <= m.settings.N; i++ {
+		for i := kChoiceRange[0]; i <= kChoiceRange[1] && i <= max(m.settings.W, m.settings.H); i++ {
+			choices = append(choices, i)
+		}
+	}
+
+	if m.choiceLevel == choiceLevelRules {
+		for i, rule := range ruleChoices {
+			if (rule == "Misère" || rule == "Ultimate") && m.settings.Players > 2 {
+				continue
+			}
+
+			choices = append(choices, i)
+		}
+	}
+
+	if m.choiceLevel == choiceLevelOpening {
+		for i := range openingChoices {
 			choices = append(choices, i)
 		}
 	}
@@ -90,6 +179,12 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.choiceLevel == choiceLevelBot {
+		for i := range bot.Kinds {
+			choices = append(choices, i)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -98,29 +193,68 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case "enter":
+			if m.choiceLevel == choiceLevelPlayers {
+				m.settings.Players = choices[m.cursor]
+			}
+
 			if m.choiceLevel == choiceLevelP {
-				p := tictactoe.P1
-				if choices[m.cursor] == 1 {
-					p = tictactoe.P2
-				}
+				m.settings.P = stoneChoices[choices[m.cursor]]
+			}
 
-				m.settings.P = p
+			if m.choiceLevel == choiceLevelW {
+				m.settings.W = choices[m.cursor]
 			}
 
-			if m.choiceLevel == choiceLevelN {
-				m.settings.N = choices[m.cursor]
+			if m.choiceLevel == choiceLevelH {
+				m.settings.H = choices[m.cursor]
 			}
 
 			if m.choiceLevel == choiceLevelK {
 				m.settings.K = choices[m.cursor]
 			}
 
+			if m.choiceLevel == choiceLevelRules {
+				switch ruleChoices[choices[m.cursor]] {
+				case "Exact":
+					m.settings.RuleSet = tictactoe.Exact
+				case "Renju":
+					m.settings.RuleSet = tictactoe.Renju
+				case "Gravity":
+					m.settings.Gravity = true
+				case "Connect6":
+					m.settings.Connect6 = true
+				case "Torus":
+					m.settings.Torus = true
+				case "Unbounded":
+					m.settings.Unbounded = true
+				case "Misère":
+					m.settings.Misere = true
+				case "Pente":
+					m.settings.Pente = true
+				case "Ultimate":
+					m.settings.Ultimate = true
+				case "Cube":
+					m.settings.Cube = true
+				}
+			}
+
+			if m.choiceLevel == choiceLevelOpening {
+				m.settings.Opening = openingChoices[choices[m.cursor]]
+			}
+
 			if m.choiceLevel == choiceLevelThink {
 				m.settings.ThinkTime = time.Duration(choices[m.cursor]) * time.Second
 			}
 
+			if m.choiceLevel == choiceLevelBot {
+				m.settings.Bot = bot.Kinds[choices[m.cursor]]
+			}
+
 			m.choiceLevel++
-			if m.choiceLevel > choiceLevelThink {
+			if m.choiceLevel == choiceLevelOpening && (m.settings.Players > 2 || m.settings.Ultimate) {
+				m.choiceLevel++
+			}
+			if m.choiceLevel > choiceLevelBot {
 				m.clear = true
 				m.done = true
 				return m, tea.Quit
@@ -156,15 +290,29 @@ func (m *model) View() string {
 	s := strings.Builder{}
 	s.WriteString(m.header)
 
+	if m.choiceLevel == choiceLevelPlayers {
+		s.WriteString("Choose number of players:\n")
+		for i := playersChoiceRange[0]; i <= playersChoiceRange[1]; i++ {
+			choices = append(choices, i)
+		}
+	}
+
 	if m.choiceLevel == choiceLevelP {
 		s.WriteString("Choose stone:\n")
-		for i := pChoiceRange[0]; i <= pChoiceRange[1]; i++ {
+		for i := range m.settings.Players {
+			choices = append(choices, i)
+		}
+	}
+
+	if m.choiceLevel == choiceLevelW {
+		s.WriteString("Choose board width:\n")
+		for i := nChoiceRange[0]; i <= nChoiceRange[1]; i++ {
 			choices = append(choices, i)
 		}
 	}
 
-	if m.choiceLevel == choiceLevelN {
-		s.WriteString("Choose board size:\n")
+	if m.choiceLevel == choiceLevelH {
+		s.WriteString("Choose board height:\n")
 		for i := nChoiceRange[0]; i <= nChoiceRange[1]; i++ {
 			choices = append(choices, i)
 		}
@@ -172,7 +320,25 @@ func (m *model) View() string {
 
 	if m.choiceLevel == choiceLevelK {
 		s.WriteString("Choose win condition:\n")
-		for i := kChoiceRange[0]; i <= kChoiceRange[1] && i <= m.settings.N; i++ {
+		for i := kChoiceRange[0]; i <= kChoiceRange[1] && i <= max(m.settings.W, m.settings.H); i++ {
+			choices = append(choices, i)
+		}
+	}
+
+	if m.choiceLevel == choiceLevelRules {
+		s.WriteString("Choose rules:\n")
+		for i, rule := range ruleChoices {
+			if (rule == "Misère" || rule == "Ultimate") && m.settings.Players > 2 {
+				continue
+			}
+
+			choices = append(choices, i)
+		}
+	}
+
+	if m.choiceLevel == choiceLevelOpening {
+		s.WriteString("Choose opening:\n")
+		for i := range openingChoices {
 			choices = append(choices, i)
 		}
 	}
@@ -184,6 +350,13 @@ func (m *model) View() string {
 		}
 	}
 
+	if m.choiceLevel == choiceLevelBot {
+		s.WriteString("Choose bot:\n")
+		for i := range bot.Kinds {
+			choices = append(choices, i)
+		}
+	}
+
 	aroundCursor := 5
 
 	minItem := m.cursor - aroundCursor
@@ -209,27 +382,45 @@ func (m *model) View() string {
 			s.WriteString(listSelectorStyle("( ) "))
 		}
 
+		if m.choiceLevel == choiceLevelPlayers {
+			s.WriteString(fmt.Sprintf("%d players", v))
+		}
+
 		if m.choiceLevel == choiceLevelP {
-			switch v {
-			case 0:
-				s.WriteString("X (first)")
-			case 1:
-				s.WriteString("O")
+			s.WriteString(stoneChoices[v].Mark())
+			if v == 0 {
+				s.WriteString(" (first)")
 			}
 		}
 
-		if m.choiceLevel == choiceLevelN {
-			s.WriteString(fmt.Sprintf("%dx%d", v, v))
+		if m.choiceLevel == choiceLevelW {
+			s.WriteString(fmt.Sprintf("%d wide", v))
+		}
+
+		if m.choiceLevel == choiceLevelH {
+			s.WriteString(fmt.Sprintf("%dx%d", m.settings.W, v))
 		}
 
 		if m.choiceLevel == choiceLevelK {
 			s.WriteString(fmt.Sprintf("%d in row", v))
 		}
 
+		if m.choiceLevel == choiceLevelRules {
+			s.WriteString(ruleChoices[v])
+		}
+
+		if m.choiceLevel == choiceLevelOpening {
+			s.WriteString(openingChoices[v].String())
+		}
+
 		if m.choiceLevel == choiceLevelThink {
 			s.WriteString(fmt.Sprintf("%ds of thinking", v))
 		}
 
+		if m.choiceLevel == choiceLevelBot {
+			s.WriteString(botChoiceNames[bot.Kinds[v]])
+		}
+
 		s.WriteString("\n")
 	}
 
@@ -1,10 +1,16 @@
 package ticntacntoen
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Zarux/ticntacntoen/internal/logger"
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
 )
 
 type httpHandler struct {
@@ -18,38 +24,212 @@ func HTTPHandler(s *Service) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("POST /{gameID}/moves/", h.HandleNewMove)
+	mux.HandleFunc("POST /{gameID}/choices/", h.HandleChoice)
+	mux.HandleFunc("POST /{gameID}/resign/", h.HandleResign)
 	mux.HandleFunc("POST /", h.HandleNewGame)
 
 	return mux
 }
 
+// moveRequest plays on X, Y, on layer Z of a cube.
 type moveRequest struct {
 	X            int           `json:"x"`
 	Y            int           `json:"y"`
+	Z            int           `json:"z,omitempty"`
+	Hash         uint64        `json:"hash"`
+	ThinkingTime time.Duration `json:"thinkingTime"`
+}
+
+// choiceRequest picks a color, or more stones under swap2, when the opening
+// waits on the player. Choice is one of the choices the board lists.
+type choiceRequest struct {
+	Choice       int8          `json:"choice"`
 	Hash         uint64        `json:"hash"`
 	ThinkingTime time.Duration `json:"thinkingTime"`
 }
 
 type board struct {
-	State  []int8 `json:"state"`
-	Hash   uint64 `json:"hash"`
-	Winner int8   `json:"winner,omitempty"`
+	ID       string `json:"id"`
+	State    []int8 `json:"state"`
+	Position string `json:"position,omitempty"`
+	Hash     uint64 `json:"hash"`
+	Status   string `json:"status"`
+	Winner   int8   `json:"winner,omitempty"`
+	Loser    int8   `json:"loser,omitempty"`
+	Line     []int  `json:"line,omitempty"`
+	Phase    string `json:"phase"`
+	Choices  []int8 `json:"choices,omitempty"`
+}
+
+func newBoard(id string, g *tictactoe.Game) board {
+	b := g.Board
+	state := make([]int8, len(b.Cells))
+	for i, p := range b.Cells {
+		state[i] = int8(p)
+	}
+
+	position, _ := b.Position()
+	status := g.Status()
+	var choices []int8
+	for _, c := range g.Choices() {
+		choices = append(choices, int8(c))
+	}
+
+	return board{
+		ID:       id,
+		State:    state,
+		Position: position,
+		Hash:     b.Hash,
+		Status:   status.Outcome.String(),
+		Winner:   int8(status.Winner),
+		Loser:    int8(status.Loser),
+		Line:     status.Line,
+		Phase:    g.Phase.String(),
+		Choices:  choices,
+	}
 }
 
 func (h *httpHandler) HandleNewMove(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	log := logger.FromContext(ctx)
-	log.Info("yo")
+	var req moveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	move := tictactoe.Move{X: req.X, Y: req.Y, Z: req.Z}
+	b, err := h.svc.NewMove(r.Context(), r.PathValue("gameID"), move, req.Hash, req.ThinkingTime)
+	h.respond(w, r, b, err)
+}
+
+func (h *httpHandler) HandleChoice(w http.ResponseWriter, r *http.Request) {
+	var req choiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.svc.Choose(r.Context(), r.PathValue("gameID"), tictactoe.Choice(req.Choice), req.Hash, req.ThinkingTime)
+	h.respond(w, r, b, err)
 }
 
+// HandleResign ends the game with the player giving up.
+func (h *httpHandler) HandleResign(w http.ResponseWriter, r *http.Request) {
+	b, err := h.svc.Resign(r.Context(), r.PathValue("gameID"))
+	h.respond(w, r, b, err)
+}
+
+// respond writes the board of the game, or the error that kept the request
+// from being played.
+func (h *httpHandler) respond(w http.ResponseWriter, r *http.Request, b *board, err error) {
+	log := logger.FromContext(r.Context())
+
+	switch {
+	case errors.Is(err, ErrGameNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, ErrStaleBoard):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil && b == nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		// The bot failed after the request was played, which is shown on
+		// the board.
+		log.Error(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(b); err != nil {
+		log.Error(err.Error())
+	}
+}
+
+// newGameRequest starts a game on an empty board, or from Position when it is
+// set, which then also gives the size and rules. Player is the color the
+// player starts as, X unless set, and the bot plays the other seat, thinking
+// for ThinkingTime on every move unless a move asks for another.
 type newGameRequest struct {
-	N      int `json:"n"`
-	K      int `json:"k"`
-	Player int `json:"player"`
+	W            int           `json:"w"`
+	H            int           `json:"h"`
+	K            int           `json:"k"`
+	Player       int           `json:"player"`
+	Opening      string        `json:"opening,omitempty"`
+	Position     string        `json:"position,omitempty"`
+	ThinkingTime time.Duration `json:"thinkingTime,omitempty"`
+}
+
+// maxSize is the longest side of a board a game can be started on, the largest
+// board bitboards cover. Boards are allocated in full, so it keeps a single
+// request from taking all of the server's memory.
+const maxSize = 19
+
+func (req newGameRequest) game() (*tictactoe.Game, error) {
+	if req.Position != "" {
+		// The size is checked before the position is read, as reading it
+		// allocates the board.
+		if fields := strings.Fields(req.Position); len(fields) > 0 {
+			for _, side := range strings.Split(fields[0], "x") {
+				if n, err := strconv.Atoi(side); err == nil && n > maxSize {
+					return nil, fmt.Errorf("board %s is larger than %d on a side", fields[0], maxSize)
+				}
+			}
+		}
+
+		return tictactoe.NewFromPosition(req.Position)
+	}
+
+	if req.W > maxSize || req.H > maxSize {
+		return nil, fmt.Errorf("board %dx%d is larger than %d on a side", req.W, req.H, maxSize)
+	}
+
+	var opts []tictactoe.Option
+	if req.Opening != "" {
+		opening, err := tictactoe.ParseOpening(req.Opening)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, tictactoe.WithOpening(opening))
+	}
+
+	return tictactoe.New(req.W, req.H, req.K, opts...)
 }
 
 func (h *httpHandler) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := logger.FromContext(ctx)
-	log.Info("yo")
+
+	var req newGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	game, err := req.game()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	player := tictactoe.P1
+	if req.Player != 0 {
+		player = tictactoe.Player(req.Player)
+	}
+
+	human := game.Seat(player)
+	if human < 0 {
+		http.Error(w, fmt.Sprintf("player %d is not in the game", req.Player), http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.svc.NewGame(ctx, game, human, req.ThinkingTime)
+	if err != nil && !errors.Is(err, ErrInvalidThinkTime) {
+		// The bot could not make its first moves, so there is no game.
+		log.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.respond(w, r, b, err)
 }
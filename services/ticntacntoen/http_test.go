@@ -0,0 +1,91 @@
+package ticntacntoen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+func TestNewGameRequestSize(t *testing.T) {
+	tests := []struct {
+		name string
+		req  newGameRequest
+		ok   bool
+	}{
+		{"smallest board", newGameRequest{W: 3, H: 3, K: 3}, true},
+		{"largest board", newGameRequest{W: maxSize, H: maxSize, K: 5}, true},
+		{"too wide", newGameRequest{W: maxSize + 1, H: 3, K: 3}, false},
+		{"too high", newGameRequest{W: 3, H: 1000, K: 3}, false},
+		{"position", newGameRequest{Position: "3x3 3 freestyle 3/3/3 X 0 -"}, true},
+		{"position too large", newGameRequest{Position: "1000x1000 5 freestyle 1000 X 0 -"}, false},
+		{"cube too deep", newGameRequest{Position: "3x3x1000 3 freestyle 3/3/3 X 0 -"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.req.game()
+			if ok := err == nil; ok != tt.ok {
+				t.Fatalf("game: %v, expected ok %t", err, tt.ok)
+			}
+
+			if g != nil && (g.Board.W > maxSize || g.Board.H > maxSize) {
+				t.Errorf("board %dx%d started", g.Board.W, g.Board.H)
+			}
+		})
+	}
+}
+
+// post sends body to path and decodes the board it answers with, if it
+// answers with status want.
+func post(t *testing.T, h http.Handler, path, body string, want int) board {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if rec.Code != want {
+		t.Fatalf("POST %s %s: status %d, expected %d: %s", path, body, rec.Code, want, rec.Body)
+	}
+
+	var b board
+	if want == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return b
+}
+
+func TestMoveOnCube(t *testing.T) {
+	h := HTTPHandler(newTestService())
+
+	b := post(t, h, "/", `{"position": "3x3x3 3 freestyle 3/3/3|3/3/3|3/3/3 X 0 -"}`, http.StatusOK)
+
+	path := "/" + b.ID + "/moves/"
+	post(t, h, path, fmt.Sprintf(`{"x": 1, "y": 1, "z": 3, "hash": %d}`, b.Hash), http.StatusBadRequest)
+	b = post(t, h, path, fmt.Sprintf(`{"x": 1, "y": 1, "z": 2, "hash": %d}`, b.Hash), http.StatusOK)
+
+	// Cells are laid out layer by layer, and the bot answers on the first
+	// free one.
+	if idx := 2*9 + 4; b.State[idx] != int8(tictactoe.P1) || b.State[0] != int8(tictactoe.P2) {
+		t.Errorf("state %v, expected X on cell %d and O on cell 0", b.State, idx)
+	}
+}
+
+func TestResign(t *testing.T) {
+	h := HTTPHandler(newTestService())
+
+	b := post(t, h, "/", `{"w": 3, "h": 3, "k": 3}`, http.StatusOK)
+	b = post(t, h, "/"+b.ID+"/resign/", ``, http.StatusOK)
+	if b.Status != tictactoe.Resigned.String() || b.Loser != int8(tictactoe.P1) || b.Winner != int8(tictactoe.P2) {
+		t.Errorf("game %s with loser %d and winner %d, expected X to resign to O", b.Status, b.Loser, b.Winner)
+	}
+
+	// The game is over and dropped, so it can not be resigned again.
+	post(t, h, "/"+b.ID+"/resign/", ``, http.StatusNotFound)
+}
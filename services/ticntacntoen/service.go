@@ -2,78 +2,312 @@ package ticntacntoen
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/bot"
 	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
 )
 
-type botPlayer interface {
-	GetNextMove(context.Context, *tictactoe.Board, tictactoe.Player) (int, error)
-}
+var (
+	ErrGameNotFound = errors.New("game not found")
+	ErrStaleBoard   = errors.New("board has changed")
+
+	ErrInvalidThinkTime = errors.New("invalid think time")
+)
+
+const (
+	// sessionTTL is how long a game is kept after it was last played.
+	sessionTTL = 30 * time.Minute
+
+	// defaultThinkTime is how long the bot thinks in games that do not say,
+	// and maxThinkTime the longest a game may ask for.
+	defaultThinkTime = time.Second
+	maxThinkTime     = time.Minute
+)
 
 type Service struct {
-	bot botPlayer
+	newBot func() bot.Bot
+
+	// mu guards games and when each was last used. Every game has a lock of
+	// its own, held while it is played, so bots think for different games at
+	// the same time.
+	mu    sync.Mutex
+	games map[string]*session
+}
+
+// session is a game played against a bot of its own from one seat.
+type session struct {
+	mu        sync.Mutex
+	game      *tictactoe.Game
+	human     tictactoe.Seat
+	bot       bot.Bot
+	thinkTime time.Duration
+
+	used time.Time
 }
 
-func New(bot botPlayer) *Service {
+// New returns a service playing with bots made by newBot. Bots keep state
+// between moves, so every game gets its own.
+func New(newBot func() bot.Bot) *Service {
 	return &Service{
-		bot: bot,
+		newBot: newBot,
+		games:  map[string]*session{},
+	}
+}
+
+// NewMove plays move for the human on the board with the given hash, and lets
+// the bot answer. A think time of 0 keeps the one the game had.
+func (s *Service) NewMove(ctx context.Context, id string, move tictactoe.Move, hash uint64, thinkTime time.Duration) (*board, error) {
+	return s.humanTurn(ctx, id, hash, thinkTime, func(g *tictactoe.Game) error {
+		b := g.Board
+		if !b.InBounds3(move.X, move.Y, move.Z) {
+			return fmt.Errorf("move %d,%d,%d is outside the board", move.X, move.Y, move.Z)
+		}
+
+		return g.PlayMove(b.GetIdx3(move.X, move.Y, move.Z))
+	})
+}
+
+// Choose makes the human's choice in the opening and lets the bot answer.
+func (s *Service) Choose(ctx context.Context, id string, choice tictactoe.Choice, hash uint64, thinkTime time.Duration) (*board, error) {
+	return s.humanTurn(ctx, id, hash, thinkTime, func(g *tictactoe.Game) error {
+		return g.Choose(choice)
+	})
+}
+
+// NewGame keeps the game to be played with the human in seat human, with the
+// bot thinking for thinkTime, or defaultThinkTime if it is 0. The bot makes the
+// first moves if they are its. Games not played for sessionTTL are dropped.
+func (s *Service) NewGame(ctx context.Context, g *tictactoe.Game, human tictactoe.Seat, thinkTime time.Duration) (*board, error) {
+	if thinkTime == 0 {
+		thinkTime = defaultThinkTime
+	}
+
+	if err := checkThinkTime(thinkTime); err != nil {
+		return nil, err
+	}
+
+	id := rand.Text()
+	sess := &session{
+		game:      g,
+		human:     human,
+		bot:       s.newBot(),
+		thinkTime: thinkTime,
+		used:      time.Now(),
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	s.mu.Lock()
+	for id, old := range s.games {
+		if time.Since(old.used) > sessionTTL {
+			delete(s.games, id)
+		}
+	}
+
+	s.games[id] = sess
+	s.mu.Unlock()
+
+	if err := s.botTurns(ctx, sess); err != nil {
+		s.forget(id)
+		return nil, err
+	}
+
+	return s.view(id, sess), nil
+}
+
+// Resign ends the game with the human giving up, whoever is to act.
+func (s *Service) Resign(ctx context.Context, id string) (*board, error) {
+	sess, err := s.session(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if err := sess.game.Resign(sess.game.Color(sess.human)); err != nil {
+		return nil, err
+	}
+
+	return s.view(id, sess), nil
+}
+
+// session returns the game with the given ID and marks it as used.
+func (s *Service) session(id string) (*session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.games[id]
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+
+	sess.used = time.Now()
+	return sess, nil
+}
+
+// humanTurn plays the human's turn with play, if they are to act on the board
+// they were shown, and lets the bot answer. The board is returned after play
+// succeeded, even if the bot then failed.
+func (s *Service) humanTurn(ctx context.Context, id string, hash uint64, thinkTime time.Duration, play func(*tictactoe.Game) error) (*board, error) {
+	sess, err := s.session(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.game.Board.Hash != hash {
+		return nil, ErrStaleBoard
+	}
+
+	if seat := sess.game.SeatToMove(); seat != sess.human {
+		return nil, fmt.Errorf("seat %d is to act, not %d", seat, sess.human)
+	}
+
+	if thinkTime != 0 {
+		if err := checkThinkTime(thinkTime); err != nil {
+			return nil, err
+		}
+
+		sess.thinkTime = thinkTime
 	}
+
+	if err := play(sess.game); err != nil {
+		return nil, err
+	}
+
+	err = s.botTurns(ctx, sess)
+	return s.view(id, sess), err
 }
 
-func (s *Service) NewMove(ctx context.Context, move tictactoe.Move, player tictactoe.Player) error {
+func checkThinkTime(t time.Duration) error {
+	if t < 0 || t > maxThinkTime {
+		return fmt.Errorf("%w: %s is not between 0 and %s", ErrInvalidThinkTime, t, maxThinkTime)
+	}
+
 	return nil
 }
 
-func (s *Service) NewGame(ctx context.Context) error {
+// view returns the board of a game to show the human, and drops the game once
+// it is over. The lock of the game is held.
+func (s *Service) view(id string, sess *session) *board {
+	if sess.game.Status().Over() {
+		s.forget(id)
+	}
+
+	b := newBoard(id, sess.game)
+	return &b
+}
+
+func (s *Service) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.games, id)
+}
+
+// botTurns lets the bot act until it is the human's turn or the game is over.
+// The lock of the game is held.
+func (s *Service) botTurns(ctx context.Context, sess *session) error {
+	sess.bot.UpdateThinkTime(sess.thinkTime)
+
+	g := sess.game
+	for !g.Status().Over() && g.SeatToMove() != sess.human {
+		if g.Phase == tictactoe.PhaseChoose {
+			choice, err := sess.bot.ChooseOpening(ctx, g)
+			if err != nil {
+				return err
+			}
+
+			if err := g.Choose(choice); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		move, err := sess.bot.GetNextMove(ctx, g.Board, g.Board.ToMove())
+		if err != nil {
+			return err
+		}
+
+		if err := g.PlayMove(move); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *Service) Play(ctx context.Context) error {
-	bot := mcts.New(2, 1_000_000)
+// Play lets a bot of its own play a game against itself, printing every move.
+func (s *Service) Play(ctx context.Context, opts ...tictactoe.Option) error {
+	bot := s.newBot()
 	bot.UpdateThinkTime(5 * time.Second)
 
-	game, err := tictactoe.New(7, 4)
+	game, err := tictactoe.New(7, 7, 4, opts...)
 	if err != nil {
 		return err
 	}
 
 	board := game.Board
 
-	turnNumber := 0
-	player := tictactoe.P1
 	for {
+		if game.Phase == tictactoe.PhaseChoose {
+			seat := game.Chooser()
+			choice, err := bot.ChooseOpening(ctx, game)
+			if err != nil {
+				return err
+			}
+
+			if err := game.Choose(choice); err != nil {
+				return err
+			}
+
+			fmt.Println("Seat", seat, "chose to", choice)
+			continue
+		}
+
 		t := time.Now()
 
+		player := board.ToMove()
 		nextMove, err := bot.GetNextMove(ctx, board, player)
 		if err != nil {
 			return err
 		}
 
-		move := board.GetMove(nextMove)
-		board.Play(player, move)
+		if err := game.PlayMove(nextMove); err != nil {
+			return err
+		}
+
 		stats := bot.Stats()
 		iterations := 0
 		if stats != nil {
 			iterations = stats.NumIterations
 		}
 
-		fmt.Println("Current player:", player.Mark(), "move:", move, "thinking for:", time.Since(t), "iterations", iterations)
+		fmt.Println("Current player:", player.Mark(), "seat:", game.Seat(player), "move:", board.FormatMove(nextMove), "thinking for:", time.Since(t), "iterations", iterations)
 		board.Print()
 
-		winner := board.CheckWinner()
-		if winner != tictactoe.Empty {
-			fmt.Println("WINNER IS:", winner.Mark())
-			break
-		} else if winner == tictactoe.Empty && !board.AnyLegalMoves() {
-			fmt.Println("DRAW")
-			break
+		status := game.Status()
+		if !status.Over() {
+			continue
 		}
 
-		player = -player
-		turnNumber++
+		if status.Winner != tictactoe.Empty {
+			fmt.Println("WINNER IS:", status.Winner.Mark(), "after", status.Ply, "plies")
+		} else {
+			fmt.Println("DRAW after", status.Ply, "plies")
+		}
+
+		fmt.Println("Moves:", board.FormatMoves(board.Moves()))
+		return nil
 	}
-	return nil
 }
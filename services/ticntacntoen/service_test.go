@@ -0,0 +1,180 @@
+package ticntacntoen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Zarux/ticntacntoen/pkg/bot"
+	"github.com/Zarux/ticntacntoen/pkg/mcts"
+	"github.com/Zarux/ticntacntoen/pkg/tictactoe"
+)
+
+// testBot plays the first legal move and keeps the first choice. It waits for
+// release first if that is set, after saying so on started.
+type testBot struct {
+	thinkTime time.Duration
+	started   chan struct{}
+	release   chan struct{}
+	err       error
+}
+
+func (b *testBot) GetNextMove(ctx context.Context, board *tictactoe.Board, p tictactoe.Player) (int, error) {
+	if b.release != nil {
+		b.started <- struct{}{}
+		<-b.release
+	}
+
+	if b.err != nil {
+		return -1, b.err
+	}
+
+	return board.LegalMoves()[0], nil
+}
+
+func (b *testBot) ChooseOpening(ctx context.Context, g *tictactoe.Game) (tictactoe.Choice, error) {
+	return g.Choices()[0], nil
+}
+
+func (b *testBot) Stats() *mcts.LastMoveStats {
+	return nil
+}
+
+func (b *testBot) UpdateThinkTime(t time.Duration) {
+	b.thinkTime = t
+}
+
+// newTestService returns a service whose bots are handed out from bots, and
+// plain test bots once they run out.
+func newTestService(bots ...*testBot) *Service {
+	return New(func() bot.Bot {
+		if len(bots) == 0 {
+			return &testBot{}
+		}
+
+		b := bots[0]
+		bots = bots[1:]
+		return b
+	})
+}
+
+func newTestGame(t *testing.T) *tictactoe.Game {
+	t.Helper()
+
+	g, err := tictactoe.New(3, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestGamesThinkAtOnce(t *testing.T) {
+	slow := &testBot{started: make(chan struct{}), release: make(chan struct{})}
+	svc := newTestService(slow)
+
+	g := newTestGame(t)
+	done := make(chan error)
+	go func() {
+		_, err := svc.NewGame(context.Background(), g, 1, 0)
+		done <- err
+	}()
+
+	<-slow.started
+
+	// The first game's bot is still thinking, which does not hold up others.
+	b, err := svc.NewGame(context.Background(), newTestGame(t), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.NewMove(context.Background(), b.ID, tictactoe.Move{X: 1, Y: 1}, b.Hash, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	close(slow.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestThinkTimePerGame(t *testing.T) {
+	first, second := &testBot{}, &testBot{}
+	svc := newTestService(first, second)
+
+	ctx := context.Background()
+	b, err := svc.NewGame(ctx, newTestGame(t), 0, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.NewGame(ctx, newTestGame(t), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.NewMove(ctx, b.ID, tictactoe.Move{X: 1, Y: 1}, b.Hash, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if first.thinkTime != 3*time.Second || second.thinkTime != defaultThinkTime {
+		t.Errorf("bots think for %s and %s, expected %s and %s", first.thinkTime, second.thinkTime, 3*time.Second, defaultThinkTime)
+	}
+
+	if _, err := svc.NewGame(ctx, newTestGame(t), 0, time.Hour); !errors.Is(err, ErrInvalidThinkTime) {
+		t.Errorf("NewGame thinking for an hour: %v, expected %v", err, ErrInvalidThinkTime)
+	}
+}
+
+func TestGamesAreDropped(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	b, err := svc.NewGame(ctx, newTestGame(t), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bot answers on the first free cell, a1 and then b1, so X wins on
+	// the diagonal.
+	for _, m := range []tictactoe.Move{{X: 1, Y: 1}, {X: 2, Y: 0}, {X: 0, Y: 2}} {
+		if b, err = svc.NewMove(ctx, b.ID, m, b.Hash, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if b.Status != tictactoe.Won.String() || b.Winner != int8(tictactoe.P1) {
+		t.Fatalf("game %s by %d, expected X to win", b.Status, b.Winner)
+	}
+
+	if _, err := svc.NewMove(ctx, b.ID, tictactoe.Move{X: 2, Y: 2}, b.Hash, 0); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("move after the game was over: %v, expected %v", err, ErrGameNotFound)
+	}
+
+	idle, err := svc.NewGame(ctx, newTestGame(t), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.games[idle.ID].used = time.Now().Add(-sessionTTL - time.Minute)
+	if _, err := svc.NewGame(ctx, newTestGame(t), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := svc.games[idle.ID]; ok {
+		t.Errorf("game idle for longer than %s was kept", sessionTTL)
+	}
+}
+
+func TestHandleNewGameBotError(t *testing.T) {
+	svc := newTestService(&testBot{err: errors.New("no moves")})
+	h := HTTPHandler(svc)
+
+	// The human asks to play O, so the bot moves first.
+	post(t, h, "/", `{"w": 3, "h": 3, "k": 3, "player": -1}`, http.StatusInternalServerError)
+
+	if len(svc.games) != 0 {
+		t.Errorf("%d games kept after the bot failed", len(svc.games))
+	}
+}